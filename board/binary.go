@@ -0,0 +1,159 @@
+package board
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// Binary format version.
+const binaryVersion = 1
+
+// ErrChecksum is returned by the binary decoders if the checksum of the data does not match.
+var ErrChecksum = errors.New("board: checksum mismatch")
+
+const (
+	boardWallsSize  = NumField / 2
+	targetSize      = 3 // coordinate, symbol, color
+	boardHeaderSize = 1 + boardWallsSize + 1 + 1
+	crcSize         = 4
+)
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+//
+// Format: version byte, the walls of all fields with 4 bits per field (low nibble:
+// even field coordinate), the number of tile IDs (0 or 4), the number of targets,
+// the tile IDs each prefixed by its length, per target the field coordinate, symbol
+// and color, and the CRC-32 (IEEE) checksum of all preceding bytes in big endian
+// byte order.
+func (b *Board) MarshalBinary() ([]byte, error) {
+	data := make([]byte, boardHeaderSize, boardHeaderSize+NumField*targetSize+crcSize)
+	data[0] = binaryVersion
+	for c, f := range b.Fields {
+		data[1+c/2] |= byte(f.Walls) << (4 * (c % 2))
+	}
+	tileIDs, ok := b.TileIDs()
+	if ok {
+		data[1+boardWallsSize] = byte(NumTile)
+		for _, id := range tileIDs {
+			data = append(data, byte(len(id)))
+			data = append(data, id...)
+		}
+	}
+	n := 0
+	for c, f := range b.Fields {
+		if f.Symbol != NoSymbol {
+			data = append(data, byte(c), byte(f.Symbol), byte(f.Color))
+			n++
+		}
+	}
+	data[1+boardWallsSize+1] = byte(n)
+	return append(data, boardChecksum(data)...), nil
+}
+
+// checkBinary verifies size, version and checksum of data and returns the data
+// without version and checksum.
+func checkBinary(data []byte, minSize, sumSize int, checksum func(data []byte) []byte) ([]byte, error) {
+	if len(data) < minSize {
+		return nil, fmt.Errorf("board: binary data too short (%d bytes)", len(data))
+	}
+	if data[0] != binaryVersion {
+		return nil, fmt.Errorf("board: unsupported binary version %d", data[0])
+	}
+	n := len(data) - sumSize
+	if string(checksum(data[:n])) != string(data[n:]) {
+		return nil, ErrChecksum
+	}
+	return data[1:n], nil
+}
+
+// boardChecksum returns the CRC-32 (IEEE) checksum of data in big endian byte order.
+func boardChecksum(data []byte) []byte {
+	return binary.BigEndian.AppendUint32(nil, crc32.ChecksumIEEE(data))
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// The derived board data is recalculated.
+func (b *Board) UnmarshalBinary(data []byte) error {
+	data, err := checkBinary(data, boardHeaderSize+crcSize, crcSize, boardChecksum)
+	if err != nil {
+		return err
+	}
+	walls, numTile, numTarget, data := data[:boardWallsSize], int(data[boardWallsSize]), int(data[boardWallsSize+1]), data[boardWallsSize+2:]
+
+	var tileIDs [NumTile]string
+	switch numTile {
+	case 0:
+	case int(NumTile):
+		for p := range tileIDs {
+			if len(data) == 0 || len(data) < 1+int(data[0]) {
+				return errors.New("board: invalid binary tile IDs")
+			}
+			tileIDs[p], data = string(data[1:1+data[0]]), data[1+data[0]:]
+			if _, ok := tiles[tileIDs[p]]; !ok {
+				return fmt.Errorf("board: invalid binary tile ID %s", tileIDs[p])
+			}
+		}
+	default:
+		return fmt.Errorf("board: invalid number of tile IDs %d", numTile)
+	}
+	if len(data) != numTarget*targetSize {
+		return fmt.Errorf("board: invalid binary target list length %d", len(data))
+	}
+
+	var fields [NumField]Field
+	for c := range fields {
+		fields[c].Walls = Wall(walls[c/2]>>(4*(c%2))) & 0x0f
+	}
+	for ; len(data) != 0; data = data[targetSize:] {
+		c, symbol, color := data[0], Symbol(data[1]), Color(data[2])
+		if symbol == NoSymbol {
+			return fmt.Errorf("board: invalid binary target %s %s", symbol, color)
+		}
+		fields[c].Symbol, fields[c].Color = symbol, color
+	}
+	if err := b.setFields(&fields); err != nil {
+		return err
+	}
+	b.tileIDs = tileIDs
+	return nil
+}
+
+// robotsBinarySize is the size of the binary robot positions: version byte, one
+// coordinate byte per robot and checksum byte.
+const robotsBinarySize = 1 + NumRobot + 1
+
+// robotsChecksum returns the lowest byte of the CRC-32 (IEEE) checksum of data.
+func robotsChecksum(data []byte) []byte { return []byte{byte(crc32.ChecksumIEEE(data))} }
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+//
+// Format: version byte, the field coordinate of each robot in robot index order and
+// the lowest byte of the CRC-32 (IEEE) checksum of the preceding bytes.
+func (r Robots) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, robotsBinarySize)
+	data = append(data, binaryVersion)
+	data = append(data, r[:]...)
+	return append(data, robotsChecksum(data)...), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (r *Robots) UnmarshalBinary(data []byte) error {
+	if len(data) != robotsBinarySize {
+		return fmt.Errorf("board: invalid binary robots size %d", len(data))
+	}
+	data, err := checkBinary(data, robotsBinarySize, 1, robotsChecksum)
+	if err != nil {
+		return err
+	}
+	for i := range data {
+		for j := 0; j < i; j++ {
+			if data[i] == data[j] {
+				return fmt.Errorf("board: robots %s and %s on the same field", RobotColors[j], RobotColors[i])
+			}
+		}
+	}
+	copy(r[:], data)
+	return nil
+}
@@ -0,0 +1,178 @@
+package board
+
+import "math/bits"
+
+// Bitboard is a set of board fields. The field with coordinate byte c is represented
+// by bit c%64 of word c/64, so each word holds four columns of sixteen fields.
+type Bitboard [4]uint64
+
+// Set adds field c to the bitboard.
+func (b *Bitboard) Set(c byte) { b[c>>6] |= 1 << (c & 63) }
+
+// Clear removes field c from the bitboard.
+func (b *Bitboard) Clear(c byte) { b[c>>6] &^= 1 << (c & 63) }
+
+// Has returns true if field c is part of the bitboard, false otherwise.
+func (b Bitboard) Has(c byte) bool { return b[c>>6]&(1<<(c&63)) != 0 }
+
+// IsEmpty returns true if the bitboard does not contain any field, false otherwise.
+func (b Bitboard) IsEmpty() bool { return b[0]|b[1]|b[2]|b[3] == 0 }
+
+// Count returns the number of fields of the bitboard.
+func (b Bitboard) Count() int {
+	return bits.OnesCount64(b[0]) + bits.OnesCount64(b[1]) + bits.OnesCount64(b[2]) + bits.OnesCount64(b[3])
+}
+
+// And returns the intersection of the bitboards b and o.
+func (b Bitboard) And(o Bitboard) Bitboard {
+	return Bitboard{b[0] & o[0], b[1] & o[1], b[2] & o[2], b[3] & o[3]}
+}
+
+// Or returns the union of the bitboards b and o.
+func (b Bitboard) Or(o Bitboard) Bitboard {
+	return Bitboard{b[0] | o[0], b[1] | o[1], b[2] | o[2], b[3] | o[3]}
+}
+
+// AndNot returns the fields of bitboard b which are not part of bitboard o.
+func (b Bitboard) AndNot(o Bitboard) Bitboard {
+	return Bitboard{b[0] &^ o[0], b[1] &^ o[1], b[2] &^ o[2], b[3] &^ o[3]}
+}
+
+// column masks excluding the bottom (y=0) and top (y=15) row
+const (
+	notBottomRow = 0xfffefffefffefffe
+	notTopRow    = 0x7fff7fff7fff7fff
+)
+
+// Shift returns the bitboard with all fields moved one field in direction d.
+// Fields moved over the board border are dropped.
+func (b Bitboard) Shift(d Direction) Bitboard {
+	switch d {
+	case North:
+		return Bitboard{
+			b[0] << 1 & notBottomRow,
+			(b[1]<<1 | b[0]>>63) & notBottomRow,
+			(b[2]<<1 | b[1]>>63) & notBottomRow,
+			(b[3]<<1 | b[2]>>63) & notBottomRow,
+		}
+	case South:
+		return Bitboard{
+			(b[0]>>1 | b[1]<<63) & notTopRow,
+			(b[1]>>1 | b[2]<<63) & notTopRow,
+			(b[2]>>1 | b[3]<<63) & notTopRow,
+			b[3] >> 1 & notTopRow,
+		}
+	case East:
+		return Bitboard{
+			b[0] << 16,
+			b[1]<<16 | b[0]>>48,
+			b[2]<<16 | b[1]>>48,
+			b[3]<<16 | b[2]>>48,
+		}
+	case West:
+		return Bitboard{
+			b[0]>>16 | b[1]<<48,
+			b[1]>>16 | b[2]<<48,
+			b[2]>>16 | b[3]<<48,
+			b[3] >> 16,
+		}
+	}
+	return b
+}
+
+// RowMask returns the bitboard containing all fields of row y.
+func RowMask(y int) Bitboard {
+	w := uint64(0x0001000100010001) << y
+	return Bitboard{w, w, w, w}
+}
+
+// ColumnMask returns the bitboard containing all fields of column x.
+func ColumnMask(x int) Bitboard {
+	var b Bitboard
+	b[x>>2] = 0xffff << ((x & 3) << 4)
+	return b
+}
+
+// next returns the lowest field >= c of the bitboard.
+func (b *Bitboard) next(c byte) (byte, bool) {
+	i := c >> 6
+	w := b[i] & (^uint64(0) << (c & 63))
+	for {
+		if w != 0 {
+			return i<<6 | byte(bits.TrailingZeros64(w)), true
+		}
+		if i++; i == 4 {
+			return 0, false
+		}
+		w = b[i]
+	}
+}
+
+// prev returns the highest field <= c of the bitboard.
+func (b *Bitboard) prev(c byte) (byte, bool) {
+	i := int(c >> 6)
+	w := b[i] & (^uint64(0) >> (63 - c&63))
+	for {
+		if w != 0 {
+			return byte(i<<6 | (63 - bits.LeadingZeros64(w))), true
+		}
+		if i--; i < 0 {
+			return 0, false
+		}
+		w = b[i]
+	}
+}
+
+// Bitboard returns the bitboard of the fields occupied by robots.
+func (r *Robots) Bitboard() Bitboard {
+	var b Bitboard
+	for _, c := range r {
+		b.Set(c)
+	}
+	return b
+}
+
+// Bitboards is the bitboard representation of a board.
+type Bitboards struct {
+	// Walls contains per direction the fields having a wall in that direction.
+	Walls [NumDirection]Bitboard
+	// Targets contains the target fields.
+	Targets Bitboard
+}
+
+// NewBitboards creates the bitboard representation of board b.
+func NewBitboards(b *Board) *Bitboards {
+	bb := &Bitboards{}
+	for c, f := range b.Fields {
+		for _, d := range Directions {
+			if f.hasWall(d.Wall()) {
+				bb.Walls[d].Set(byte(c))
+			}
+		}
+		if f.Symbol != NoSymbol {
+			bb.Targets.Set(byte(c))
+		}
+	}
+	return bb
+}
+
+// Stop returns the field a robot located on field c stops when moving in direction d.
+// Parameter robots is the bitboard of the fields occupied by robots.
+func (bb *Bitboards) Stop(c byte, d Direction, robots Bitboard) byte {
+	// a robot stops on fields with a wall or a robot in move direction
+	blocked := bb.Walls[d].Or(robots.Shift(d.Reverse()))
+	var stop byte
+	switch d {
+	case North: // the top wall guarantees a stop in the same column
+		stop, _ = blocked.next(c)
+	case South: // the bottom wall guarantees a stop in the same column
+		stop, _ = blocked.prev(c)
+	case East:
+		blocked = blocked.And(RowMask(int(c & 0x0f)))
+		stop, _ = blocked.next(c)
+	case West:
+		blocked = blocked.And(RowMask(int(c & 0x0f)))
+		stop, _ = blocked.prev(c)
+	}
+	return stop
+}
@@ -3,6 +3,8 @@ package board
 
 import (
 	"fmt"
+	"hash/fnv"
+	"sync"
 
 	"github.com/go-ricrob/game/coord"
 )
@@ -72,13 +74,13 @@ func (t *Targets) calcTargets(b *Board, x0, y0 int) {
 	t.South.X, t.South.Y = x0, y
 	// east
 	x := x0
-	for b.Field(x, y).Walls&EastWall == 0 {
+	for b.Field(x, y0).Walls&EastWall == 0 {
 		x++
 	}
 	t.East.X, t.East.Y = x, y0
 	// west
 	x = x0
-	for b.Field(x, y).Walls&WestWall == 0 {
+	for b.Field(x, y0).Walls&WestWall == 0 {
 		x--
 	}
 	t.West.X, t.West.Y = x, y0
@@ -104,14 +106,76 @@ func (f *Field) addWall(walls ...Wall) {
 	}
 }
 
+// Stops is the type of the precomputed stop fields of a board. For each field and
+// direction it holds the field a robot stops at when moving from the field in the
+// direction without being blocked by other robots.
+type Stops [NumField][NumDirection]byte
+
+func (s *Stops) calcStops(b *Board) {
+	for c := 0; c < NumField; c++ {
+		for _, d := range Directions {
+			w, delta := d.Wall(), directionDeltas[d]
+			stop := byte(c)
+			for !b.Fields[stop].hasWall(w) {
+				stop += delta
+			}
+			s[c][d] = stop
+		}
+	}
+}
+
+type target struct {
+	symbol Symbol
+	color  Color
+}
+
 // Board is the type representing a board.
+//
+// A board may be used by several goroutines at the same time as long as it is
+// not edited. Edits (AddWalls, RemoveWalls, SetTarget) rewrite the fields and the
+// derived data like Stops without locking and must not run concurrently with any
+// other use of the board, including searches and TargetMinMoves.
+//
+// Writing the fields directly through Fields is not an edit: the Stops table and
+// the cached min moves tables are not updated and get stale. Use the edit methods
+// or NewFromFields instead.
 type Board struct {
 	Fields [NumField]*Field
+	Stops  Stops
+
+	tileIDs [NumTile]string // tiles the board is composed of, empty if edited
+
+	mu       sync.Mutex                // guards minMoves only
+	minMoves map[target]*[NumField]int // cached MinMoves tables per target
 }
 
 // New creates a new board instance. Parameter tiles needs to be valid - if not NewBoard will panic.
 func New(tileIDs [NumTile]string) *Board {
 	b := &Board{}
+	if err := b.setTiles(tileIDs); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// NewFromTiles creates a new board instance from the tiles given by tileIDs. In
+// contrast to New an error is returned for invalid tile IDs.
+func NewFromTiles(tileIDs [NumTile]string) (*Board, error) {
+	b := &Board{}
+	if err := b.setTiles(tileIDs); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// setTiles sets the fields of the board to the fields of the tiles given by tileIDs.
+func (b *Board) setTiles(tileIDs [NumTile]string) error {
+	for _, id := range tileIDs {
+		if _, ok := tiles[id]; !ok {
+			return fmt.Errorf("invalid tileID: %v", id)
+		}
+	}
+
 	// init fields
 	for i := 0; i < NumField; i++ {
 		b.Fields[i] = new(Field)
@@ -119,11 +183,7 @@ func New(tileIDs [NumTile]string) *Board {
 
 	// set tile fields
 	for p, id := range tileIDs {
-		fields, ok := tiles[id]
-		if !ok {
-			panic(fmt.Errorf("invalid tileID: %v", id))
-		}
-		for c, f := range fields {
+		for c, f := range tiles[id] {
 			x, y := posShifts[p](posRotations[p](coord.X(c), coord.Y(c))) // rotate and shift
 			field := b.Fields[coord.Ctob(x, y)]
 			field.Walls = wallRotations[p](f.Walls)
@@ -132,7 +192,23 @@ func New(tileIDs [NumTile]string) *Board {
 		}
 	}
 
-	// set outer walls
+	b.setOuterWalls()
+
+	// set center walls
+	b.Fields[coord.Ctob(numTileField-1, numTileField-1)].addWall(WestWall, SouthWall) // bottom left center field
+	b.Fields[coord.Ctob(numTileField-1, numTileField)].addWall(WestWall, NorthWall)   // top left center field
+	b.Fields[coord.Ctob(numTileField, numTileField-1)].addWall(EastWall, SouthWall)   // bottom right center field
+	b.Fields[coord.Ctob(numTileField, numTileField)].addWall(EastWall, NorthWall)     // top right center field
+
+	// finally: set neighbor walls
+	b.setNeighborWalls()
+
+	b.tileIDs = tileIDs
+	b.invalidate()
+	return nil
+}
+
+func (b *Board) setOuterWalls() {
 	for x := 0; x < numBoardField; x++ {
 		b.Fields[coord.Ctob(x, numBoardField-1)].addWall(NorthWall) // top border
 		b.Fields[coord.Ctob(x, 0)].addWall(SouthWall)               // bottom border
@@ -141,14 +217,9 @@ func New(tileIDs [NumTile]string) *Board {
 		b.Fields[coord.Ctob(0, y)].addWall(WestWall)               // left border
 		b.Fields[coord.Ctob(numBoardField-1, y)].addWall(EastWall) // right border
 	}
+}
 
-	// set center walls
-	b.Fields[coord.Ctob(numTileField-1, numTileField-1)].addWall(WestWall, SouthWall) // bottom left center field
-	b.Fields[coord.Ctob(numTileField-1, numTileField)].addWall(WestWall, NorthWall)   // top left center field
-	b.Fields[coord.Ctob(numTileField, numTileField-1)].addWall(EastWall, SouthWall)   // bottom right center field
-	b.Fields[coord.Ctob(numTileField, numTileField)].addWall(EastWall, NorthWall)     // top right center field
-
-	// finally: set neighbor walls
+func (b *Board) setNeighborWalls() {
 	for x := 0; x < numBoardField; x++ {
 		for y := 0; y < numBoardField; y++ {
 			// if west field has east wall -> set west wall
@@ -169,16 +240,17 @@ func New(tileIDs [NumTile]string) *Board {
 			}
 		}
 	}
+}
 
-	// calculate routes
+// calculate routes
+func (b *Board) calcRoutes() {
 	for x := 0; x < numBoardField; x++ {
 		for y := 0; y < numBoardField; y++ {
 			field := b.Field(x, y)
 			field.Targets.calcTargets(b, x, y)
 		}
 	}
-
-	return b
+	b.Stops.calcStops(b)
 }
 
 // IsValidCoordinate returns true if the coordinate represents a valid board field, false otherwise.
@@ -196,9 +268,35 @@ func (b *Board) IsValidCoordinate(x, y int) bool {
 	return true
 }
 
+// TileIDs returns the IDs of the tiles the board is composed of and false if the board
+// was edited.
+func (b *Board) TileIDs() ([NumTile]string, bool) {
+	return b.tileIDs, b.tileIDs != [NumTile]string{}
+}
+
+// ID returns an identifier of the board derived from walls and targets of all fields.
+// Equal boards have equal IDs.
+func (b *Board) ID() string {
+	h := fnv.New64a()
+	for _, f := range b.Fields {
+		h.Write([]byte{byte(f.Walls), byte(f.Symbol), byte(f.Color)})
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
 // Field returns the board field at position x,y.
 func (b *Board) Field(x, y int) *Field { return b.Fields[coord.Ctob(x, y)] }
 
+// HasTarget reports whether the board holds the target given by symbol and color.
+func (b *Board) HasTarget(symbol Symbol, color Color) bool {
+	for _, field := range b.Fields {
+		if field.Symbol != NoSymbol && field.Symbol == symbol && field.Color == color {
+			return true
+		}
+	}
+	return false
+}
+
 // TargetCoord returns the coordinate of the target.
 func (b *Board) TargetCoord(symbol Symbol, color Color) byte {
 	for idx, field := range b.Fields {
@@ -218,63 +316,70 @@ func (b *Board) MinMoves(cr byte) [NumField]int {
 		minMoves[i] = -1
 	}
 
+	// each field is added to at most one source or target list
+	var buf [4][NumField]byte
+	hsource, vsource := append(buf[0][:0], cr), append(buf[1][:0], cr)
+	htarget, vtarget := buf[2][:0], buf[3][:0]
 	minMoves[cr] = 0
-	hsource := []byte{cr}
-	vsource := []byte{cr}
-	htarget := []byte{}
-	vtarget := []byte{}
+
+	// visit all fields between c (excluded) and stop field of direction d (included)
+	visit := func(c byte, d Direction, moves int, target []byte) []byte {
+		stop, delta := b.Stops[c][d], directionDeltas[d]
+		for c != stop {
+			c += delta
+			if minMoves[c] == -1 {
+				minMoves[c] = moves
+				target = append(target, c)
+			}
+		}
+		return target
+	}
 
 	moves := 0
 	for len(hsource) != 0 || len(vsource) != 0 {
 		moves++
 		for _, ch := range hsource {
-			x0, y0 := coord.Btoc(ch)
-
-			x := x0
-			for !b.Field(x, y0).hasWall(WestWall) {
-				x--
-				c := coord.Ctob(x, y0)
-				if minMoves[c] == -1 {
-					minMoves[c] = moves
-					vtarget = append(vtarget, c)
-				}
-			}
-			x = x0
-			for !b.Field(x, y0).hasWall(EastWall) {
-				x++
-				c := coord.Ctob(x, y0)
-				if minMoves[c] == -1 {
-					minMoves[c] = moves
-					vtarget = append(vtarget, c)
-				}
-			}
+			vtarget = visit(ch, West, moves, vtarget)
+			vtarget = visit(ch, East, moves, vtarget)
 		}
-
 		for _, cv := range vsource {
-			x0, y0 := coord.Btoc(cv)
-
-			y := y0
-			for !b.Field(x0, y).hasWall(SouthWall) {
-				y--
-				c := coord.Ctob(x0, y)
-				if minMoves[c] == -1 {
-					minMoves[c] = moves
-					htarget = append(htarget, c)
-				}
-			}
-			y = y0
-			for !b.Field(x0, y).hasWall(NorthWall) {
-				y++
-				c := coord.Ctob(x0, y)
-				if minMoves[c] == -1 {
-					minMoves[c] = moves
-					htarget = append(htarget, c)
-				}
-			}
+			htarget = visit(cv, South, moves, htarget)
+			htarget = visit(cv, North, moves, htarget)
 		}
+		hsource, htarget = htarget, hsource[:0]
+		vsource, vtarget = vtarget, vsource[:0]
+	}
+	return minMoves
+}
 
-		hsource, vsource = htarget, vtarget
-		htarget, vtarget = nil, nil
+// TargetMinMoves returns the minimal moves from each field to the target field
+// of the target given by symbol and color (see MinMoves).
+// The tables of all targets are calculated on first use and are cached until the
+// board is edited. The returned table is shared and must not be modified.
+func (b *Board) TargetMinMoves(symbol Symbol, color Color) *[NumField]int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.minMoves == nil {
+		b.minMoves = map[target]*[NumField]int{}
+		for c, field := range b.Fields {
+			if field.Symbol != NoSymbol {
+				minMoves := b.MinMoves(byte(c))
+				b.minMoves[target{field.Symbol, field.Color}] = &minMoves
+			}
+		}
+	}
+	minMoves, ok := b.minMoves[target{symbol, color}]
+	if !ok {
+		panic(fmt.Errorf("invalid target: symbol %s color %s", symbol, color))
 	}
 	return minMoves
 }
+
+// invalidate recalculates the derived board data after an edit. The stop and
+// route tables are rewritten without holding mu, see the Board documentation.
+func (b *Board) invalidate() {
+	b.calcRoutes()
+	b.mu.Lock()
+	b.minMoves = nil
+	b.mu.Unlock()
+}
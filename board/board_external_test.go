@@ -0,0 +1,374 @@
+package board_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"testing/quick"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/coord"
+	"github.com/go-ricrob/game/internal/testutil"
+)
+
+func BenchmarkNew(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		board.New(testutil.DefaultTiles)
+	}
+}
+
+func BenchmarkMinMoves(b *testing.B) {
+	bd := board.New(testutil.DefaultTiles)
+	target := bd.TargetCoord(board.Star, board.Red)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bd.MinMoves(target)
+	}
+}
+
+func BenchmarkStop(b *testing.B) {
+	bd := board.New(testutil.DefaultTiles)
+	robots := board.Robots{coord.Ctob(0, 0), coord.Ctob(5, 3), coord.Ctob(12, 3), coord.Ctob(5, 12)}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for c := 0; c < board.NumField; c++ {
+			for _, d := range board.Directions {
+				bd.Stop(byte(c), d, &robots)
+			}
+		}
+	}
+}
+
+func BenchmarkBitboardsStop(b *testing.B) {
+	bb := board.NewBitboards(board.New(testutil.DefaultTiles))
+	robots := board.Robots{coord.Ctob(0, 0), coord.Ctob(5, 3), coord.Ctob(12, 3), coord.Ctob(5, 12)}
+	occupied := robots.Bitboard()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for c := 0; c < board.NumField; c++ {
+			for _, d := range board.Directions {
+				bb.Stop(byte(c), d, occupied)
+			}
+		}
+	}
+}
+
+func TestSources(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	sources := func(robots board.Robots, c byte, d byte) bool {
+		dir := board.Direction(d % byte(board.NumDirection))
+		robots[0] = c // moving robot
+		if robots[1] == c || robots[2] == c || robots[3] == c {
+			return true
+		}
+		var expected board.Bitboard
+		for s := 0; s < board.NumField; s++ {
+			r := robots
+			r[0] = byte(s)
+			if byte(s) != c && !robots.Occupied(byte(s)) && b.Stop(byte(s), dir, &r) == c {
+				expected.Set(byte(s))
+			}
+		}
+		var got board.Bitboard
+		for _, s := range b.Sources(nil, c, dir, &robots) {
+			got.Set(s)
+		}
+		return got == expected
+	}
+	if err := quick.Check(sources, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStops(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	for c, f := range b.Fields {
+		stops := b.Stops[c]
+		targets := [board.NumDirection]coord.XY{board.North: f.Targets.North, board.East: f.Targets.East, board.South: f.Targets.South, board.West: f.Targets.West}
+		for d, xy := range targets {
+			if stops[d] != coord.Ctob(xy.X, xy.Y) {
+				x, y := coord.Btoc(byte(c))
+				t.Fatalf("field %d,%d direction %s: stop %d,%d - expected %d,%d", x, y, board.Direction(d), coord.X(stops[d]), coord.Y(stops[d]), xy.X, xy.Y)
+			}
+		}
+	}
+}
+
+func TestBitboards(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	bb := board.NewBitboards(b)
+
+	for c, f := range b.Fields {
+		for _, d := range board.Directions {
+			if bb.Walls[d].Has(byte(c)) != (f.Walls&d.Wall() != 0) {
+				t.Fatalf("field %d direction %s: invalid wall", c, d)
+			}
+		}
+		if bb.Targets.Has(byte(c)) != (f.Symbol != board.NoSymbol) {
+			t.Fatalf("field %d: invalid target", c)
+		}
+	}
+
+	shift := func(fields board.Bitboard, d byte) bool {
+		dir := board.Direction(d % byte(board.NumDirection))
+		shifted := fields.Shift(dir)
+		for c := 0; c < board.NumField; c++ {
+			x, y := coord.Btoc(byte(c))
+			switch dir {
+			case board.North:
+				y--
+			case board.East:
+				x--
+			case board.South:
+				y++
+			case board.West:
+				x++
+			}
+			expected := x >= 0 && x < 16 && y >= 0 && y < 16 && fields.Has(coord.Ctob(x, y))
+			if shifted.Has(byte(c)) != expected {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(shift, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := func(robots board.Robots, c byte, d byte) bool {
+		dir := board.Direction(d % byte(board.NumDirection))
+		return bb.Stop(c, dir, robots.Bitboard()) == b.Stop(c, dir, &robots)
+	}
+	if err := quick.Check(stop, &quick.Config{MaxCount: 10000}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTargetMinMoves(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+
+	var wg sync.WaitGroup
+	for _, symbol := range board.Symbols {
+		for _, color := range board.RobotColors {
+			if symbol == board.Cosmic {
+				color = 0
+			}
+			wg.Add(1)
+			go func(symbol board.Symbol, color board.Color) {
+				defer wg.Done()
+				if *b.TargetMinMoves(symbol, color) != b.MinMoves(b.TargetCoord(symbol, color)) {
+					t.Errorf("target %s %s: invalid min moves", symbol, color)
+				}
+			}(symbol, color)
+		}
+	}
+	wg.Wait()
+
+	// edit board: the red star at 2,10 gets unreachable from the north
+	before := *b.TargetMinMoves(board.Star, board.Red)
+	if err := b.AddWalls(2, 10, board.NorthWall); err != nil {
+		t.Fatal(err)
+	}
+	after := b.TargetMinMoves(board.Star, board.Red)
+	if *after == before {
+		t.Fatal("min moves not invalidated after edit")
+	}
+	if *after != b.MinMoves(b.TargetCoord(board.Star, board.Red)) {
+		t.Fatal("invalid min moves after edit")
+	}
+}
+
+func TestEdit(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	id := b.ID()
+	for name, err := range map[string]error{
+		"add walls x":        b.AddWalls(16, 0, board.NorthWall),
+		"add walls y":        b.AddWalls(0, -1, board.NorthWall),
+		"add invalid walls":  b.AddWalls(0, 0, 16),
+		"remove walls":       b.RemoveWalls(0, 16, board.NorthWall),
+		"set target":         b.SetTarget(-1, 0, board.Star, board.Red),
+		"duplicate target":   b.SetTarget(5, 5, board.Star, board.Red),
+		"cosmic with color":  b.SetTarget(5, 5, board.Cosmic, board.Red),
+		"star without color": b.SetTarget(5, 5, board.Star, 0),
+		"invalid symbol":     b.SetTarget(5, 5, board.Cosmic+1, board.Red),
+	} {
+		if err == nil {
+			t.Fatalf("%s: error expected", name)
+		}
+	}
+	if b.ID() != id {
+		t.Fatal("board changed by invalid edits")
+	}
+
+	// setting a target on its own field again, removing it and setting it elsewhere
+	c := b.TargetCoord(board.Star, board.Red)
+	if err := b.SetTarget(int(c>>4), int(c&0x0f), board.Star, board.Red); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.SetTarget(int(c>>4), int(c&0x0f), board.NoSymbol, board.Red); err != nil {
+		t.Fatal(err)
+	}
+	if f := b.Fields[c]; f.Symbol != board.NoSymbol || f.Color != 0 {
+		t.Fatalf("removed target: field %s", f)
+	}
+	if err := b.SetTarget(5, 5, board.Star, board.Red); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setATileIDs returns the tile IDs of all arrangements of the tiles of set A.
+func setATileIDs() [][board.NumTile]string {
+	var arrangements [][board.NumTile]string
+	var permute func(nos []int, k int)
+	permute = func(nos []int, k int) {
+		if k == len(nos) {
+			for sides := 0; sides < 1<<board.NumTile; sides++ {
+				var ids [board.NumTile]string
+				for p, no := range nos {
+					side := 'F'
+					if sides&(1<<p) != 0 {
+						side = 'B'
+					}
+					ids[p] = fmt.Sprintf("A%d%c", no, side)
+				}
+				arrangements = append(arrangements, ids)
+			}
+			return
+		}
+		for i := k; i < len(nos); i++ {
+			nos[k], nos[i] = nos[i], nos[k]
+			permute(nos, k+1)
+			nos[k], nos[i] = nos[i], nos[k]
+		}
+	}
+	permute([]int{1, 2, 3, 4}, 0)
+	return arrangements
+}
+
+func checkEqualBoards(t *testing.T, b1, b2 *board.Board) {
+	t.Helper()
+	for c := range b1.Fields {
+		if *b1.Fields[c] != *b2.Fields[c] {
+			t.Fatalf("field %d,%d: %s - expected %s", c>>4, c&0x0f, b2.Fields[c], b1.Fields[c])
+		}
+	}
+	if b1.Stops != b2.Stops {
+		t.Fatal("stop tables differ")
+	}
+	ids1, ok1 := b1.TileIDs()
+	ids2, ok2 := b2.TileIDs()
+	if ids1 != ids2 || ok1 != ok2 {
+		t.Fatalf("tile IDs %v %t - expected %v %t", ids2, ok2, ids1, ok1)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	arrangements := setATileIDs()
+	if len(arrangements) != 384 {
+		t.Fatalf("%d arrangements - expected 384", len(arrangements))
+	}
+	for _, ids := range arrangements {
+		b := board.New(ids)
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b2 := new(board.Board)
+		if err := json.Unmarshal(data, b2); err != nil {
+			t.Fatalf("%v: %s", ids, err)
+		}
+		checkEqualBoards(t, b, b2)
+	}
+
+	// edited board
+	b := board.New(testutil.DefaultTiles)
+	if err := b.AddWalls(2, 10, board.NorthWall); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.SetTarget(5, 5, board.Star, board.Silver); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b2 := new(board.Board)
+	if err := json.Unmarshal(data, b2); err != nil {
+		t.Fatal(err)
+	}
+	checkEqualBoards(t, b, b2)
+	if b2.TargetMinMoves(board.Star, board.Silver) == nil {
+		t.Fatal("min moves of target not available")
+	}
+
+	for _, data := range []string{
+		`{"tiles":{"topleft":"A1F","topright":"A2F","bottomleft":"A4F","bottomright":"X3F"}}`,
+		`{"tiles":{"topleft":"A1F","topright":"A2F","bottomleft":"A4F"}}`,
+		`{"tiles":{"topleft":"A1F"},"fields":[{"x":0,"y":0}]}`,
+		`{"fields":[{"x":16,"y":0}]}`,
+		`{"fields":[{"x":0,"y":0,"walls":16}]}`,
+	} {
+		if err := json.Unmarshal([]byte(data), new(board.Board)); err == nil {
+			t.Fatalf("%s: error expected", data)
+		}
+	}
+}
+
+func TestBinary(t *testing.T) {
+	boards := []*board.Board{board.New(testutil.DefaultTiles)}
+	b := board.New(testutil.DefaultTiles)
+	if err := b.AddWalls(2, 10, board.NorthWall); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.SetTarget(5, 5, board.Star, board.Silver); err != nil {
+		t.Fatal(err)
+	}
+	boards = append(boards, b)
+	for _, ids := range setATileIDs()[:10] {
+		boards = append(boards, board.New(ids))
+	}
+	for _, b := range boards {
+		data, err := b.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b2 := new(board.Board)
+		if err := b2.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		checkEqualBoards(t, b, b2)
+
+		for _, i := range []int{0, 1, len(data) / 2, len(data) - 1} {
+			corrupt := bytes.Clone(data)
+			corrupt[i] ^= 0x10
+			if err := new(board.Board).UnmarshalBinary(corrupt); err == nil {
+				t.Fatalf("corrupt byte %d: error expected", i)
+			}
+		}
+		if err := new(board.Board).UnmarshalBinary(data[:len(data)-1]); err == nil {
+			t.Fatal("truncated data: error expected")
+		}
+	}
+
+	f := func(r board.Robots) bool {
+		data, err := r.MarshalBinary()
+		if err != nil || len(data) != 1+board.NumRobot+1 {
+			return false
+		}
+		var r2 board.Robots
+		err = r2.UnmarshalBinary(data)
+		if r[0] == r[1] || r[0] == r[2] || r[0] == r[3] || r[1] == r[2] || r[1] == r[3] || r[2] == r[3] {
+			return err != nil
+		}
+		if err != nil || r2 != r {
+			return false
+		}
+		data[2] ^= 0x01
+		return r2.UnmarshalBinary(data) != nil
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Fatal(err)
+	}
+}
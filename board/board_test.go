@@ -1,6 +1,8 @@
 package board
 
 import (
+	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/go-ricrob/game/coord"
@@ -210,3 +212,81 @@ func TestBoard(t *testing.T) {
 		})
 	}
 }
+
+func TestNotation(t *testing.T) {
+	moves := []Move{{Red, North}, {Blue, East}, {Red, West}, {Yellow, South}, {Green, North}}
+	const arrows, ascii = "R↑ B→ R← Y↓ G↑", "RN BE RW YS GN"
+	if s := FormatMoves(moves); s != arrows {
+		t.Fatalf("got %q - expected %q", s, arrows)
+	}
+	if s := FormatMovesASCII(moves); s != ascii {
+		t.Fatalf("got %q - expected %q", s, ascii)
+	}
+	if s := moves[0].String(); s != "R↑" {
+		t.Fatalf("got %q - expected %q", s, "R↑")
+	}
+	for _, s := range []string{arrows, ascii} {
+		got, err := ParseMoves(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, moves) {
+			t.Fatalf("%q: got %v - expected %v", s, got, moves)
+		}
+	}
+	if got, err := ParseMoves(""); err != nil || len(got) != 0 {
+		t.Fatalf("empty notation: got %v %v - expected no moves", got, err)
+	}
+
+	tests := []struct {
+		s      string
+		column int
+	}{
+		{" RN", 1},
+		{"RN ", 4},
+		{"RN  BE", 4},
+		{"RNBE", 3},
+		{"R", 2},
+		{"XN", 1},
+		{"rN", 1},
+		{"RX", 2},
+		{"R↑ B↑ Gx", 8},
+		{"R↑ BE", 5},
+		{"RN B→", 5},
+		{"\xff", 1},
+	}
+	for _, test := range tests {
+		_, err := ParseMoves(test.s)
+		var nerr *NotationError
+		if !errors.As(err, &nerr) {
+			t.Fatalf("%q: notation error expected - got %v", test.s, err)
+		}
+		if nerr.Column != test.column {
+			t.Fatalf("%q: got column %d - expected %d (%s)", test.s, nerr.Column, test.column, err)
+		}
+	}
+}
+
+func TestLetters(t *testing.T) {
+	for _, s := range Symbols {
+		if got, ok := SymbolByLetter(s.Letter()); !ok || got != s {
+			t.Fatalf("symbol %s: letter %q returns %s", s, s.Letter(), got)
+		}
+	}
+	for _, c := range []Color{Yellow, Red, Green, Blue, Silver} {
+		if got, ok := ColorByLetter(c.Letter()); !ok || got != c {
+			t.Fatalf("color %s: letter %q returns %s", c, c.Letter(), got)
+		}
+	}
+	if Symbol(NoSymbol).Letter() != 0 || Color(0).Letter() != 0 || Symbol(99).Letter() != 0 {
+		t.Fatal("letter of no symbol or color expected to be 0")
+	}
+	for _, l := range []byte{0, ' ', 'x', 'p'} {
+		if _, ok := SymbolByLetter(l); ok {
+			t.Fatalf("symbol letter %q: not ok expected", l)
+		}
+		if _, ok := ColorByLetter(l); ok {
+			t.Fatalf("color letter %q: not ok expected", l)
+		}
+	}
+}
@@ -25,3 +25,25 @@ func (c Color) String() string {
 	}
 	return colorStrs[c]
 }
+
+var colorLetters = [...]byte{Yellow: 'y', Red: 'r', Green: 'g', Blue: 'b', Silver: 's'}
+
+// Letter returns the letter of the target color used by the text representations
+// of boards and 0 for no color and invalid colors. Robots are written with the
+// capital letters (see RobotLetters).
+func (c Color) Letter() byte {
+	if int(c) >= len(colorLetters) {
+		return 0
+	}
+	return colorLetters[c]
+}
+
+// ColorByLetter returns the color of letter l and false if l is not a color letter.
+func ColorByLetter(l byte) (Color, bool) {
+	for c, cl := range colorLetters {
+		if l != 0 && cl == l {
+			return Color(c), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,41 @@
+package board
+
+import "fmt"
+
+// Direction is the type of a robot move direction.
+type Direction byte
+
+// Direction constants.
+const (
+	North Direction = iota
+	East
+	South
+	West
+	NumDirection
+)
+
+var directionStrs = []string{"north", "east", "south", "west"}
+
+func (d Direction) String() string {
+	if int(d) >= len(directionStrs) {
+		return fmt.Sprintf("invalid direction %d", d)
+	}
+	return directionStrs[d]
+}
+
+// Wall returns the wall which stops a move in direction d.
+func (d Direction) Wall() Wall { return NorthWall << d }
+
+// Reverse returns the opposite direction.
+func (d Direction) Reverse() Direction { return (d + 2) % NumDirection }
+
+// Directions is the set of valid directions.
+var Directions = []Direction{North, East, South, West}
+
+// coordinate byte deltas (x is stored in the high, y in the low nibble)
+var directionDeltas = [NumDirection]byte{
+	North: 1,
+	East:  1 << 4,
+	South: 0xff, // -1
+	West:  0xf0, // -16
+}
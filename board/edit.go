@@ -0,0 +1,131 @@
+package board
+
+import (
+	"fmt"
+
+	"github.com/go-ricrob/game/coord"
+)
+
+// neighbor returns the coordinates of the neighbor field of field x,y in direction d
+// and false if there is no neighbor field.
+func neighbor(x, y int, d Direction) (int, int, bool) {
+	switch d {
+	case North:
+		y++
+	case East:
+		x++
+	case South:
+		y--
+	case West:
+		x--
+	}
+	return x, y, x >= 0 && x < numBoardField && y >= 0 && y < numBoardField
+}
+
+// NewFromFields creates a new board instance from the walls and targets of fields.
+// Walls at the board border and the opposite walls of neighbor fields are added.
+func NewFromFields(fields *[NumField]Field) (*Board, error) {
+	b := &Board{}
+	if err := b.setFields(fields); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// setFields sets the walls and targets of the board fields to the ones of fields.
+func (b *Board) setFields(fields *[NumField]Field) error {
+	for c, f := range fields {
+		if f.Walls > NorthWall|EastWall|SouthWall|WestWall || f.Symbol > Cosmic || f.Color > Silver {
+			return fmt.Errorf("board: invalid field %d,%d: %v", coord.X(byte(c)), coord.Y(byte(c)), &f)
+		}
+	}
+	for c, f := range fields {
+		b.Fields[c] = &Field{Walls: f.Walls, Symbol: f.Symbol, Color: f.Color}
+	}
+	b.setOuterWalls()
+	b.setNeighborWalls()
+	b.edited()
+	return nil
+}
+
+// checkField returns an error if x,y is not a field of the board.
+func checkField(x, y int) error {
+	if x < 0 || x >= numBoardField || y < 0 || y >= numBoardField {
+		return fmt.Errorf("board: invalid field %d,%d", x, y)
+	}
+	return nil
+}
+
+// AddWalls adds walls w to field x,y and the opposite walls to the neighbor fields.
+func (b *Board) AddWalls(x, y int, w Wall) error {
+	if err := checkField(x, y); err != nil {
+		return err
+	}
+	if w > NorthWall|EastWall|SouthWall|WestWall {
+		return fmt.Errorf("board: invalid walls %d", w)
+	}
+	for _, d := range Directions {
+		if w&d.Wall() == 0 {
+			continue
+		}
+		b.Field(x, y).addWall(d.Wall())
+		if nx, ny, ok := neighbor(x, y, d); ok {
+			b.Field(nx, ny).addWall(d.Reverse().Wall())
+		}
+	}
+	b.edited()
+	return nil
+}
+
+// RemoveWalls removes walls w from field x,y and the opposite walls from the neighbor fields.
+// Walls at the board border cannot be removed.
+func (b *Board) RemoveWalls(x, y int, w Wall) error {
+	if err := checkField(x, y); err != nil {
+		return err
+	}
+	if w > NorthWall|EastWall|SouthWall|WestWall {
+		return fmt.Errorf("board: invalid walls %d", w)
+	}
+	for _, d := range Directions {
+		if w&d.Wall() == 0 {
+			continue
+		}
+		if nx, ny, ok := neighbor(x, y, d); ok {
+			b.Field(x, y).Walls &^= d.Wall()
+			b.Field(nx, ny).Walls &^= d.Reverse().Wall()
+		}
+	}
+	b.edited()
+	return nil
+}
+
+// SetTarget sets the target symbol and color of field x,y. Symbol NoSymbol removes the
+// target. Cosmic targets have no color, the other targets a robot color or silver. An
+// error is returned if another field holds the same target.
+func (b *Board) SetTarget(x, y int, symbol Symbol, color Color) error {
+	if err := checkField(x, y); err != nil {
+		return err
+	}
+	switch {
+	case symbol == NoSymbol:
+		color = 0
+	case symbol == Cosmic && color == 0:
+	case symbol >= Pyramid && symbol <= Saturn && color >= Yellow && color <= Silver:
+	default:
+		return fmt.Errorf("board: invalid target: symbol %s color %s", symbol, color)
+	}
+	f := b.Field(x, y)
+	if symbol != NoSymbol && b.HasTarget(symbol, color) && (f.Symbol != symbol || f.Color != color) {
+		return fmt.Errorf("board: duplicate target: symbol %s color %s", symbol, color)
+	}
+	f.Symbol, f.Color = symbol, color
+	b.edited()
+	return nil
+}
+
+// edited marks the board as edited and recalculates the derived board data.
+// Like all edits it must not run concurrently with other uses of the board.
+func (b *Board) edited() {
+	b.tileIDs = [NumTile]string{}
+	b.invalidate()
+}
@@ -0,0 +1,75 @@
+package board
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-ricrob/game/coord"
+)
+
+type boardJSON struct {
+	Tiles  map[string]string `json:"tiles,omitempty"`  // tile position -> tile ID
+	Fields []fieldJSON       `json:"fields,omitempty"` // fields with walls or target
+}
+
+type fieldJSON struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	Field
+}
+
+// MarshalJSON implements the json.Marshaler interface. A board composed of tiles is
+// encoded by its tile IDs, an edited board by the walls and targets of its fields.
+func (b *Board) MarshalJSON() ([]byte, error) {
+	var v boardJSON
+	if tileIDs, ok := b.TileIDs(); ok {
+		v.Tiles = map[string]string{}
+		for p, id := range tileIDs {
+			v.Tiles[Tile(p).String()] = id
+		}
+	} else {
+		for c, f := range b.Fields {
+			if f.Walls != 0 || f.Symbol != NoSymbol {
+				v.Fields = append(v.Fields, fieldJSON{X: coord.X(byte(c)), Y: coord.Y(byte(c)), Field: Field{Walls: f.Walls, Symbol: f.Symbol, Color: f.Color}})
+			}
+		}
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The derived board data is
+// recalculated. Walls of fields not encoded are added: walls at the board border and
+// the opposite walls of neighbor fields.
+func (b *Board) UnmarshalJSON(data []byte) error {
+	var v boardJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch {
+	case v.Tiles != nil && v.Fields != nil:
+		return errors.New("board: both tiles and fields defined")
+	case v.Tiles != nil:
+		var tileIDs [NumTile]string
+		if len(v.Tiles) != int(NumTile) {
+			return fmt.Errorf("board: invalid number of tiles %d", len(v.Tiles))
+		}
+		for p := range tileIDs {
+			id, ok := v.Tiles[Tile(p).String()]
+			if !ok {
+				return fmt.Errorf("board: missing tile %s", Tile(p))
+			}
+			tileIDs[p] = id
+		}
+		return b.setTiles(tileIDs)
+	default:
+		var fields [NumField]Field
+		for _, f := range v.Fields {
+			if f.X < 0 || f.X >= numBoardField || f.Y < 0 || f.Y >= numBoardField {
+				return fmt.Errorf("board: invalid field coordinate %d,%d", f.X, f.Y)
+			}
+			fields[coord.Ctob(f.X, f.Y)] = f.Field
+		}
+		return b.setFields(&fields)
+	}
+}
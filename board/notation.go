@@ -0,0 +1,123 @@
+package board
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Move notation: a move is written as the robot letter Y, R, G or B followed by the
+// direction, either as arrow ↑ → ↓ ← or in ASCII as N, E, S, W. Moves are
+// separated by a single blank, e.g. "R↑ B→ R←" or "RN BE RW".
+
+var (
+	directionArrows = [NumDirection]rune{'↑', '→', '↓', '←'}
+	directionLetter = [NumDirection]byte{'N', 'E', 'S', 'W'}
+)
+
+// NotationError is the error returned by ParseMoves for malformed move notation.
+type NotationError struct {
+	Column int // 1-based column (in characters) of the error
+	Msg    string
+}
+
+func (e *NotationError) Error() string {
+	return fmt.Sprintf("board: move notation column %d: %s", e.Column, e.Msg)
+}
+
+func formatMoves(moves []Move, direction func(d Direction) string) string {
+	var sb strings.Builder
+	for i, m := range moves {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		if i := RobotIndex(m.Robot); i != -1 {
+			sb.WriteByte(RobotLetters[i])
+		} else {
+			sb.WriteByte('?')
+		}
+		if m.Direction < NumDirection {
+			sb.WriteString(direction(m.Direction))
+		} else {
+			sb.WriteByte('?')
+		}
+	}
+	return sb.String()
+}
+
+// FormatMoves returns the move notation of moves using arrows, e.g. "R↑ B→ R←".
+// This is the canonical notation. Invalid robots and directions are written as '?'.
+func FormatMoves(moves []Move) string {
+	return formatMoves(moves, func(d Direction) string { return string(directionArrows[d]) })
+}
+
+// FormatMovesASCII returns the move notation of moves using ASCII direction letters,
+// e.g. "RN BE RW". Invalid robots and directions are written as '?'.
+func FormatMovesASCII(moves []Move) string {
+	return formatMoves(moves, func(d Direction) string { return string(directionLetter[d]) })
+}
+
+// ParseMoves parses the move notation s written in arrow or in ASCII notation.
+// Both notations must not be mixed. The empty string is parsed as no moves. A
+// NotationError is returned for malformed notation.
+func ParseMoves(s string) ([]Move, error) {
+	var moves []Move
+	col := 1
+	errorf := func(format string, args ...any) error {
+		return &NotationError{Column: col, Msg: fmt.Sprintf(format, args...)}
+	}
+	ascii := false // notation of the first move
+	for len(s) != 0 {
+		if len(moves) != 0 {
+			if s[0] != ' ' {
+				return nil, errorf("blank expected between moves")
+			}
+			s, col = s[1:], col+1
+			if len(s) == 0 {
+				return nil, errorf("move expected after blank")
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(s)
+		robot := strings.IndexRune(string(RobotLetters[:]), r)
+		if robot == -1 {
+			if r == ' ' {
+				return nil, errorf("unexpected blank")
+			}
+			return nil, errorf("invalid robot %q - expected Y, R, G or B", r)
+		}
+		s, col = s[size:], col+1
+
+		if len(s) == 0 {
+			return nil, errorf("direction expected")
+		}
+		r, size = utf8.DecodeRuneInString(s)
+		d, isASCII, ok := lookupDirection(r)
+		if !ok {
+			return nil, errorf("invalid direction %q - expected ↑, →, ↓, ← or N, E, S, W", r)
+		}
+		if len(moves) == 0 {
+			ascii = isASCII
+		} else if ascii != isASCII {
+			return nil, errorf("arrow and ASCII notation mixed")
+		}
+		s, col = s[size:], col+1
+
+		moves = append(moves, Move{Robot: RobotColors[robot], Direction: d})
+	}
+	return moves, nil
+}
+
+// lookupDirection returns the direction of arrow or ASCII letter r and whether r is
+// an ASCII letter.
+func lookupDirection(r rune) (d Direction, ascii, ok bool) {
+	for d := North; d < NumDirection; d++ {
+		switch r {
+		case directionArrows[d]:
+			return d, false, true
+		case rune(directionLetter[d]):
+			return d, true, true
+		}
+	}
+	return 0, false, false
+}
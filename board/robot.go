@@ -0,0 +1,83 @@
+package board
+
+import "fmt"
+
+// NumRobot is the number of robots.
+const NumRobot = 4
+
+// RobotColors are the robot colors in robot index order.
+var RobotColors = [NumRobot]Color{Yellow, Red, Green, Blue}
+
+// RobotLetters are the letters of the robots in robot index order, used by the
+// move notation and the text representations of boards.
+var RobotLetters = [NumRobot]byte{'Y', 'R', 'G', 'B'}
+
+// RobotIndex returns the robot index of a robot color or -1 if color is not a robot color.
+func RobotIndex(c Color) int {
+	if c < Yellow || c > Blue {
+		return -1
+	}
+	return int(c - Yellow)
+}
+
+// Robots represents the robot positions as coordinate bytes in robot index order.
+type Robots [NumRobot]byte
+
+// Occupied returns true if a robot is located on field c, false otherwise.
+func (r *Robots) Occupied(c byte) bool {
+	return r[0] == c || r[1] == c || r[2] == c || r[3] == c
+}
+
+// Move is the type representing a robot move.
+type Move struct {
+	Robot     Color
+	Direction Direction
+}
+
+// String returns the move notation of m, e.g. "R↑" (see FormatMoves).
+func (m Move) String() string { return FormatMoves([]Move{m}) }
+
+// Stop returns the field a robot located on field c stops when moving in direction d.
+// A robot is stopped by walls and by other robots. The wall stop field is taken from
+// the stop table, so only the fields up to this stop field are checked for robots.
+func (b *Board) Stop(c byte, d Direction, robots *Robots) byte {
+	stop, delta := b.Stops[c][d], directionDeltas[d]
+	for c != stop {
+		n := c + delta
+		if robots.Occupied(n) {
+			return c
+		}
+		c = n
+	}
+	return c
+}
+
+// Move returns the robot positions after executing move m.
+func (b *Board) Move(robots Robots, m Move) Robots {
+	i := RobotIndex(m.Robot)
+	if i == -1 {
+		panic(fmt.Errorf("invalid robot: %s", m.Robot))
+	}
+	robots[i] = b.Stop(robots[i], m.Direction, &robots)
+	return robots
+}
+
+// Sources appends to buf all fields from which a robot moving in direction d stops on
+// field c and returns the extended buffer. The moving robot is expected to be located
+// on field c, all other robots are blockers. If a robot cannot stop on field c when
+// moving in direction d no field is appended.
+func (b *Board) Sources(buf []byte, c byte, d Direction, robots *Robots) []byte {
+	// stop on field c only if blocked by a wall or a robot
+	if !b.Fields[c].hasWall(d.Wall()) && !robots.Occupied(c+directionDeltas[d]) {
+		return buf
+	}
+	w, delta := d.Reverse().Wall(), directionDeltas[d.Reverse()]
+	for !b.Fields[c].hasWall(w) {
+		c += delta
+		if robots.Occupied(c) {
+			break
+		}
+		buf = append(buf, c)
+	}
+	return buf
+}
@@ -26,3 +26,24 @@ func (s Symbol) String() string {
 
 // Symbols is the set of valid symbols.
 var Symbols = []Symbol{Pyramid, Star, Moon, Saturn, Cosmic}
+
+var symbolLetters = [...]byte{Pyramid: 'P', Star: 'S', Moon: 'M', Saturn: 'T', Cosmic: 'C'}
+
+// Letter returns the letter of the symbol used by the text representations of boards
+// and 0 for NoSymbol and invalid symbols.
+func (s Symbol) Letter() byte {
+	if int(s) >= len(symbolLetters) {
+		return 0
+	}
+	return symbolLetters[s]
+}
+
+// SymbolByLetter returns the symbol of letter l and false if l is not a symbol letter.
+func SymbolByLetter(l byte) (Symbol, bool) {
+	for s, sl := range symbolLetters {
+		if l != 0 && sl == l {
+			return Symbol(s), true
+		}
+	}
+	return NoSymbol, false
+}
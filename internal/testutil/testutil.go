@@ -0,0 +1,68 @@
+// Package testutil provides the fixtures shared by the tests of the module's packages.
+// As it imports package board, the tests of package board can use it from the
+// external test package board_test only.
+package testutil
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/go-ricrob/game/board"
+)
+
+// DefaultTiles are the tiles of the default test board.
+var DefaultTiles = [board.NumTile]string{
+	board.TopLeft:     "A1F",
+	board.TopRight:    "A2F",
+	board.BottomRight: "A3F",
+	board.BottomLeft:  "A4F",
+}
+
+// Robots are robot positions valid on the default test board.
+var Robots = board.Robots{0x0a, 0x3c, 0x55, 0xa2}
+
+// Puzzle is a puzzle: robot positions and target.
+type Puzzle struct {
+	Robots board.Robots
+	Symbol board.Symbol
+	Color  board.Color
+}
+
+// RandomPuzzles returns n random puzzles of board b. The puzzles are the same for
+// every call with the same board.
+func RandomPuzzles(b *board.Board, n int) []Puzzle {
+	rnd := rand.New(rand.NewSource(1))
+	puzzles := make([]Puzzle, n)
+	for i := range puzzles {
+		p := &puzzles[i]
+		for j := range p.Robots {
+			for {
+				c := byte(rnd.Intn(board.NumField))
+				x, y := int(c>>4), int(c&0x0f)
+				if b.IsValidCoordinate(x, y) && !p.Robots.Occupied(c) {
+					p.Robots[j] = c
+					break
+				}
+			}
+		}
+		p.Symbol = board.Symbols[rnd.Intn(len(board.Symbols))]
+		if p.Symbol != board.Cosmic {
+			p.Color = board.RobotColors[rnd.Intn(board.NumRobot)]
+		}
+	}
+	return puzzles
+}
+
+// CheckSolution fails the test if solution sol does not move a robot of the target
+// color (any robot for cosmic targets) of puzzle p onto the target.
+func CheckSolution(t testing.TB, b *board.Board, p Puzzle, sol []board.Move) {
+	t.Helper()
+	r := p.Robots
+	for _, m := range sol {
+		r = b.Move(r, m)
+	}
+	target := b.TargetCoord(p.Symbol, p.Color)
+	if i := board.RobotIndex(p.Color); i != -1 && r[i] != target || i == -1 && !r.Occupied(target) {
+		t.Fatalf("%v: solution %s does not reach target", p, board.FormatMoves(sol))
+	}
+}
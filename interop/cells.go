@@ -0,0 +1,122 @@
+package interop
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-ricrob/game/board"
+)
+
+// Cell list: a cell is "X" for a field without walls and target, otherwise the
+// letters of its walls (N, E, S, W) followed by the target token, the color letter
+// (R, G, B, Y) and the shape letter (C, T, Q, H), e.g. "SEYH". Cells are separated
+// by commas, blanks and line breaks around cells are ignored.
+
+var cellWalls = []struct {
+	w  board.Wall
+	ch byte
+}{{board.NorthWall, 'N'}, {board.EastWall, 'E'}, {board.SouthWall, 'S'}, {board.WestWall, 'W'}}
+
+// ExportCells returns the cell list of board b, 16 cells per line. ErrUnsupported
+// is returned if the board has cosmic or silver targets.
+func ExportCells(b *board.Board) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < board.NumField; i++ {
+		f := b.Fields[fieldCoord(i)]
+		cell := make([]byte, 0, 6)
+		for _, cw := range cellWalls {
+			if f.Walls&cw.w != 0 {
+				cell = append(cell, cw.ch)
+			}
+		}
+		if f.Symbol != board.NoSymbol {
+			color, ok1 := colorLetters[f.Color]
+			shape, ok2 := shapeLetters[f.Symbol]
+			if !ok1 || !ok2 {
+				return "", fmt.Errorf("%w: target %s %s", ErrUnsupported, f.Symbol, f.Color)
+			}
+			cell = append(cell, color, shape)
+		}
+		if len(cell) == 0 {
+			cell = append(cell, 'X')
+		}
+		sb.Write(cell)
+		switch {
+		case i == board.NumField-1:
+			sb.WriteByte('\n')
+		case i%numField == numField-1:
+			sb.WriteString(",\n")
+		default:
+			sb.WriteByte(',')
+		}
+	}
+	return sb.String(), nil
+}
+
+// ImportCells returns the board of cell list s. A trailing comma is allowed.
+func ImportCells(s string) (*board.Board, error) {
+	cells := strings.Split(s, ",")
+	if len(cells) == board.NumField+1 && strings.TrimSpace(cells[board.NumField]) == "" {
+		cells = cells[:board.NumField]
+	}
+	if len(cells) != board.NumField {
+		return nil, fmt.Errorf("interop: %d cells - expected %d", len(cells), board.NumField)
+	}
+
+	var fields [board.NumField]board.Field
+	seen := map[[2]byte]bool{}
+	for i, cell := range cells {
+		cell = strings.TrimSpace(cell)
+		f := &fields[fieldCoord(i)]
+		if err := parseCell(cell, f); err != nil {
+			return nil, fmt.Errorf("interop: cell %d %q: %w", i, cell, err)
+		}
+		if f.Symbol != board.NoSymbol {
+			token := [2]byte{byte(f.Symbol), byte(f.Color)}
+			if seen[token] {
+				return nil, fmt.Errorf("interop: cell %d %q: duplicate target", i, cell)
+			}
+			seen[token] = true
+		}
+	}
+	return board.NewFromFields(&fields)
+}
+
+// parseCell parses cell into field f.
+func parseCell(cell string, f *board.Field) error {
+	if cell == "X" {
+		return nil
+	}
+	if cell == "" {
+		return fmt.Errorf("empty cell")
+	}
+	i := 0
+	for ; i < len(cell); i++ {
+		w := board.Wall(0)
+		for _, cw := range cellWalls {
+			if cell[i] == cw.ch {
+				w = cw.w
+			}
+		}
+		if w == 0 {
+			break
+		}
+		if f.Walls&w != 0 {
+			return fmt.Errorf("duplicate wall %c", cell[i])
+		}
+		f.Walls |= w
+	}
+	switch token := cell[i:]; len(token) {
+	case 0:
+	case 2:
+		color, ok1 := lookupColor(token[0])
+		symbol, ok2 := lookupShape(token[1])
+		if !ok1 || !ok2 {
+			return fmt.Errorf("invalid token %q", token)
+		}
+		f.Symbol, f.Color = symbol, color
+	default:
+		return fmt.Errorf("invalid token %q", token)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package interop
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-ricrob/game/board"
+)
+
+// Grid bit masks.
+const (
+	gridNorth = 0x01
+	gridEast  = 0x02
+	gridSouth = 0x04
+	gridWest  = 0x08
+	gridRobot = 0x10
+)
+
+var gridWalls = []struct {
+	w    board.Wall
+	mask int
+}{{board.NorthWall, gridNorth}, {board.EastWall, gridEast}, {board.SouthWall, gridSouth}, {board.WestWall, gridWest}}
+
+// gridJSON is the grid format: a wall bit mask per field (robot fields are marked
+// by an additional bit), the index of the robot to move to the target, the field
+// index of the target and the field indices of the robots.
+type gridJSON struct {
+	Grid   []int `json:"grid"`
+	Robot  int   `json:"robot"`
+	Token  int   `json:"token"`
+	Robots []int `json:"robots"`
+}
+
+// ExportGrid returns the grid of position p. The grid holds no target symbols, only
+// the field of the target of p. ErrUnsupported is returned for cosmic and silver
+// targets.
+func ExportGrid(p *Position) ([]byte, error) {
+	robot := -1
+	for i, color := range robotColors {
+		if color == p.Color {
+			robot = i
+		}
+	}
+	if robot == -1 || p.Symbol == board.Cosmic {
+		return nil, fmt.Errorf("%w: target %s %s", ErrUnsupported, p.Symbol, p.Color)
+	}
+	target := -1
+	for c, f := range p.Board.Fields {
+		if f.Symbol == p.Symbol && f.Color == p.Color {
+			target = index(byte(c))
+		}
+	}
+	if target == -1 {
+		return nil, fmt.Errorf("interop: no target %s %s on board", p.Symbol, p.Color)
+	}
+
+	v := gridJSON{Grid: make([]int, board.NumField), Robot: robot, Token: target}
+	for c, f := range p.Board.Fields {
+		mask := 0
+		for _, gw := range gridWalls {
+			if f.Walls&gw.w != 0 {
+				mask |= gw.mask
+			}
+		}
+		if p.Robots.Occupied(byte(c)) {
+			mask |= gridRobot
+		}
+		v.Grid[index(byte(c))] = mask
+	}
+	for _, color := range robotColors {
+		v.Robots = append(v.Robots, index(p.Robots[board.RobotIndex(color)]))
+	}
+	return json.Marshal(v)
+}
+
+// ImportGrid returns the position of grid data. As the grid holds no target
+// symbols, the board gets a single target of symbol symbol and the color of the
+// robot to move on the target field. The robot bits of the grid are ignored.
+func ImportGrid(data []byte, symbol board.Symbol) (*Position, error) {
+	if _, ok := shapeLetters[symbol]; !ok {
+		return nil, fmt.Errorf("interop: invalid target symbol %s", symbol)
+	}
+	var v gridJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("interop: %w", err)
+	}
+	switch {
+	case len(v.Grid) != board.NumField:
+		return nil, fmt.Errorf("interop: grid of %d fields - expected %d", len(v.Grid), board.NumField)
+	case len(v.Robots) != board.NumRobot:
+		return nil, fmt.Errorf("interop: %d robots - expected %d", len(v.Robots), board.NumRobot)
+	case v.Robot < 0 || v.Robot >= board.NumRobot:
+		return nil, fmt.Errorf("interop: invalid robot %d", v.Robot)
+	case !validIndex(v.Token):
+		return nil, fmt.Errorf("interop: invalid target field %d", v.Token)
+	}
+
+	var fields [board.NumField]board.Field
+	for i, mask := range v.Grid {
+		if mask < 0 || mask > gridNorth|gridEast|gridSouth|gridWest|gridRobot {
+			return nil, fmt.Errorf("interop: invalid mask %d of field %d", mask, i)
+		}
+		f := &fields[fieldCoord(i)]
+		for _, gw := range gridWalls {
+			if mask&gw.mask != 0 {
+				f.Walls |= gw.w
+			}
+		}
+	}
+	p := &Position{Symbol: symbol, Color: robotColors[v.Robot]}
+	f := &fields[fieldCoord(v.Token)]
+	f.Symbol, f.Color = p.Symbol, p.Color
+
+	seen := map[int]bool{}
+	for i, idx := range v.Robots {
+		if !validIndex(idx) {
+			return nil, fmt.Errorf("interop: invalid robot field %d", idx)
+		}
+		if seen[idx] {
+			return nil, errors.New("interop: robots on the same field")
+		}
+		seen[idx] = true
+		p.Robots[board.RobotIndex(robotColors[i])] = fieldCoord(idx)
+	}
+
+	var err error
+	if p.Board, err = board.NewFromFields(&fields); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func validIndex(i int) bool { return i >= 0 && i < board.NumField }
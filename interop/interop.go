@@ -0,0 +1,89 @@
+// Package interop converts boards and robot positions to and from the formats of
+// other Ricochet Robots tools, so that results can be cross-checked and puzzle
+// collections imported.
+//
+// Supported are two formats of Michael Fogleman's Ricochet Robots solver and web
+// application (github.com/fogleman/Ricochet), which many other solvers reuse:
+//
+//   - the cell list (ImportCells, ExportCells): the board as 256 comma separated
+//     cells, each cell listing its walls and target token.
+//   - the grid (ImportGrid, ExportGrid): the JSON puzzle description handed to the
+//     C solver, holding the walls as bit masks, the robot positions and the
+//     current target.
+//
+// and, independent of it, the puzzle input of the "Ricochet Robots" programming
+// contest problem on Kattis (ImportKattis, ExportKattis): a grid of up to 16x16
+// cells with blocked cells instead of walls, up to four robots, one target and a
+// move limit.
+//
+// The formats are implemented from the public source and problem statement, not
+// from a specification, and have not been verified against files produced by these
+// tools. The following details are conventions of this package where the
+// originals are either silent or could not be confirmed:
+//
+//   - Fields are indexed row by row starting at the top left corner, index =
+//     16*row + column with row 0 being y = 15.
+//   - Fogleman's robots are listed in the order red, green, blue, yellow.
+//   - Fogleman's target shapes map to symbols as circle (C) - moon, triangle (T) -
+//     pyramid, square (Q) - saturn, hexagon (H) - star. Cosmic and silver targets
+//     have no counterpart and cannot be exported.
+//   - Blocked Kattis cells are fields with walls on all sides; Kattis robots 1 to 4
+//     are the yellow, red, green and blue robot.
+//
+// Malformed input results in an error, never in a panic.
+package interop
+
+import (
+	"errors"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/coord"
+)
+
+// ErrUnsupported is returned if a board or target cannot be represented in a format.
+var ErrUnsupported = errors.New("interop: not supported by format")
+
+const numField = 16
+
+// robotColors are the robot colors in Fogleman's order.
+var robotColors = [board.NumRobot]board.Color{board.Red, board.Green, board.Blue, board.Yellow}
+
+var (
+	colorLetters = map[board.Color]byte{board.Red: 'R', board.Green: 'G', board.Blue: 'B', board.Yellow: 'Y'}
+	shapeLetters = map[board.Symbol]byte{board.Moon: 'C', board.Pyramid: 'T', board.Saturn: 'Q', board.Star: 'H'}
+)
+
+// Position is a puzzle: board, robot positions and target.
+type Position struct {
+	Board  *board.Board
+	Robots board.Robots
+	Symbol board.Symbol
+	Color  board.Color
+}
+
+// index returns the Fogleman field index of field c.
+func index(c byte) int {
+	x, y := coord.Btoc(c)
+	return (numField-1-y)*numField + x
+}
+
+// fieldCoord returns the field coordinate of Fogleman field index i.
+func fieldCoord(i int) byte { return coord.Ctob(i%numField, numField-1-i/numField) }
+
+func lookupColor(ch byte) (board.Color, bool) {
+	for color, c := range colorLetters {
+		if c == ch {
+			return color, true
+		}
+	}
+	return 0, false
+}
+
+func lookupShape(ch byte) (board.Symbol, bool) {
+	for symbol, c := range shapeLetters {
+		if c == ch {
+			return symbol, true
+		}
+	}
+	return board.NoSymbol, false
+}
@@ -0,0 +1,255 @@
+package interop
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/coord"
+	"github.com/go-ricrob/game/internal/testutil"
+	"github.com/go-ricrob/game/solver"
+)
+
+// testBoard returns the default board without the cosmic target, which the formats
+// cannot represent.
+func testBoard() *board.Board {
+	b := board.New(testutil.DefaultTiles)
+	c := b.TargetCoord(board.Cosmic, 0)
+	if err := b.SetTarget(int(c>>4), int(c&0x0f), board.NoSymbol, 0); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func checkEqualFields(t *testing.T, got, want *board.Board) {
+	t.Helper()
+	for c := range want.Fields {
+		if *got.Fields[c] != *want.Fields[c] {
+			t.Fatalf("field %x: got %v - expected %v", c, got.Fields[c], want.Fields[c])
+		}
+	}
+}
+
+func TestCells(t *testing.T) {
+	if _, err := ExportCells(board.New(testutil.DefaultTiles)); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("cosmic target: got %v - expected %v", err, ErrUnsupported)
+	}
+
+	b := testBoard()
+	s, err := ExportCells(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(s, "\n"); n != numField {
+		t.Fatalf("got %d lines - expected %d", n, numField)
+	}
+	got, err := ImportCells(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEqualFields(t, got, b)
+
+	// hand written cells: top left field with a yellow hexagon, rest empty
+	cells := make([]string, board.NumField)
+	for i := range cells {
+		cells[i] = "X"
+	}
+	cells[0], cells[17] = "SEYH", "W"
+	got, err = ImportCells(strings.Join(cells, ",") + ",")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := got.Field(0, 15)
+	if f.Walls != board.NorthWall|board.EastWall|board.SouthWall|board.WestWall || f.Symbol != board.Star || f.Color != board.Yellow {
+		t.Fatalf("field 0,15: got %v", f)
+	}
+	if got.Field(1, 14).Walls != board.WestWall || got.Field(0, 14).Walls != board.EastWall|board.NorthWall|board.WestWall {
+		t.Fatalf("fields 1,14 and 0,14: got %v %v", got.Field(1, 14), got.Field(0, 14))
+	}
+
+	for _, s := range []string{
+		"",
+		"X,X",
+		strings.Repeat("X,", board.NumField+1),
+		strings.Replace(s, "X", "", 1),
+		strings.Replace(s, "X", "NN", 1),
+		strings.Replace(s, "X", "NRX", 1),
+		strings.Replace(s, "X", "NPH", 1),
+		strings.Replace(s, "X", "RH", 1), // duplicate target
+	} {
+		if _, err := ImportCells(s); err == nil {
+			t.Fatalf("%.40q...: error expected", s)
+		}
+	}
+}
+
+func TestGrid(t *testing.T) {
+	b := testBoard()
+	p := &Position{Board: b, Robots: testutil.Robots, Symbol: board.Star, Color: board.Red}
+	data, err := ExportGrid(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ImportGrid(data, board.Star)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Robots != p.Robots || got.Symbol != p.Symbol || got.Color != p.Color {
+		t.Fatalf("got %v %s %s - expected %v %s %s", got.Robots, got.Symbol, got.Color, p.Robots, p.Symbol, p.Color)
+	}
+	if got.Board.TargetCoord(p.Symbol, p.Color) != b.TargetCoord(p.Symbol, p.Color) {
+		t.Fatal("target field differs")
+	}
+	for c, f := range got.Board.Fields {
+		if f.Walls != b.Fields[c].Walls {
+			t.Fatalf("field %x: got walls %v - expected %v", c, f.Walls, b.Fields[c].Walls)
+		}
+	}
+
+	// cross-check: the imported puzzle has the same optimal number of moves
+	want, err := solver.Solve(context.Background(), b, p.Robots, p.Symbol, p.Color)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sol, err := solver.Solve(context.Background(), got.Board, got.Robots, got.Symbol, got.Color)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sol[0]) != len(want[0]) {
+		t.Fatalf("got %d moves - expected %d", len(sol[0]), len(want[0]))
+	}
+
+	if _, err := ExportGrid(&Position{Board: b, Robots: testutil.Robots, Symbol: board.Cosmic}); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("cosmic target: got %v - expected %v", err, ErrUnsupported)
+	}
+
+	grid := strings.TrimSuffix(string(data), "}")
+	for _, s := range []string{
+		"",
+		"[]",
+		`{"grid":[1,2,3],"robot":0,"token":0,"robots":[0,1,2,3]}`,
+		strings.Replace(string(data), `"robot":0`, `"robot":4`, 1),
+		strings.Replace(string(data), `"grid":[`, `"grid":[32,`, 1),
+		strings.Replace(string(data), `"grid":[`, `"grid":["x",`, 1),
+		grid[:strings.Index(grid, `"robots"`)] + `"robots":[1,2,3]}`,
+		grid[:strings.Index(grid, `"robots"`)] + `"robots":[1,2,3,256]}`,
+		grid[:strings.Index(grid, `"robots"`)] + `"robots":[1,2,3,1]}`,
+	} {
+		if _, err := ImportGrid([]byte(s), board.Star); err == nil {
+			t.Fatalf("%.40q...: error expected", s)
+		}
+	}
+	if _, err := ImportGrid(data, board.Cosmic); err == nil {
+		t.Fatal("cosmic symbol: error expected")
+	}
+}
+
+func FuzzImportCells(f *testing.F) {
+	s, err := ExportCells(testBoard())
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(s)
+	f.Add("X,NW,SEYH")
+	f.Fuzz(func(t *testing.T, s string) {
+		ImportCells(s)
+	})
+}
+
+func FuzzImportGrid(f *testing.F) {
+	data, err := ExportGrid(&Position{Board: testBoard(), Robots: testutil.Robots, Symbol: board.Star, Color: board.Red})
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(data)
+	f.Add([]byte(`{"grid":[],"robots":[]}`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ImportGrid(data, board.Star)
+	})
+}
+
+// kattisPuzzle is a hand written Kattis puzzle.
+const kattisPuzzle = `2 5 4 10
+.2...
+...W.
+WWW..
+.X.1.
+`
+
+func TestKattis(t *testing.T) {
+	p, limit, err := ImportKattis([]byte(kattisPuzzle), board.Moon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit != 10 || p.Symbol != board.Moon || p.Color != board.Yellow {
+		t.Fatalf("got limit %d target %s %s - expected limit 10 target moon yellow", limit, p.Symbol, p.Color)
+	}
+	if c := p.Board.TargetCoord(board.Moon, board.Yellow); c != coord.Ctob(1, 12) {
+		t.Fatalf("got target field %x - expected %x", c, coord.Ctob(1, 12))
+	}
+	if p.Robots[0] != coord.Ctob(3, 12) || p.Robots[1] != coord.Ctob(1, 15) {
+		t.Fatalf("got robots %v", p.Robots)
+	}
+	// robot 1 needs a blocker on the target row: 1↑ 2→ 2↓ 2← 1↓ 1←
+	sol, err := solver.Solve(context.Background(), p.Board, p.Robots, p.Symbol, p.Color)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sol[0]) != 6 {
+		t.Fatalf("solution %s - expected 6 moves", sol[0])
+	}
+
+	data, err := ExportKattis(p, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != kattisPuzzle {
+		t.Fatalf("got\n%s- expected\n%s", data, kattisPuzzle)
+	}
+
+	for _, p := range []*Position{
+		{Board: testBoard(), Robots: testutil.Robots, Symbol: board.Star, Color: board.Red}, // walls
+		{Board: p.Board, Robots: p.Robots, Symbol: board.Cosmic},
+	} {
+		if _, err := ExportKattis(p, 10); !errors.Is(err, ErrUnsupported) {
+			t.Fatalf("got %v - expected %v", err, ErrUnsupported)
+		}
+	}
+	if _, err := ExportKattis(p, -1); err == nil {
+		t.Fatal("negative move limit: error expected")
+	}
+
+	for _, s := range []string{
+		"",
+		"2 5 4",
+		"2 5 4 x\n",
+		"0 5 4 10\n",
+		"2 17 4 10\n",
+		strings.Replace(kattisPuzzle, ".2...", ".2..", 1),
+		strings.Replace(kattisPuzzle, ".2...", ".2.X.", 1),
+		strings.Replace(kattisPuzzle, ".2...", ".2.1.", 1),
+		strings.Replace(kattisPuzzle, ".2...", ".....", 1),
+		strings.Replace(kattisPuzzle, ".2...", ".2.3.", 1),
+		strings.Replace(kattisPuzzle, ".X.1.", "...1.", 1),
+		strings.Replace(kattisPuzzle, ".2...", ".2.?.", 1),
+		strings.TrimSuffix(kattisPuzzle, ".X.1.\n"),
+		"1 16 16 10\n1X" + strings.Repeat(".", 14) + strings.Repeat("\n"+strings.Repeat(".", 16), 15), // no blocked field for robots 2-4
+	} {
+		if _, _, err := ImportKattis([]byte(s), board.Moon); err == nil {
+			t.Fatalf("%.40q...: error expected", s)
+		}
+	}
+	if _, _, err := ImportKattis([]byte(kattisPuzzle), board.Cosmic); err == nil {
+		t.Fatal("cosmic symbol: error expected")
+	}
+}
+
+func FuzzImportKattis(f *testing.F) {
+	f.Add([]byte(kattisPuzzle))
+	f.Add([]byte("1 1 1 0\nX\n"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ImportKattis(data, board.Moon)
+	})
+}
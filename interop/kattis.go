@@ -0,0 +1,235 @@
+package interop
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/coord"
+)
+
+// Kattis puzzle: a header line "n w h l" with the number of robots n (1-4), the
+// width w and the height h of the grid and the move limit l, followed by h lines
+// of w cells: '.' empty, 'W' blocked, 'X' target, '1' to 'n' robots. Robot 1 has
+// to reach the target.
+
+const allWalls = board.NorthWall | board.EastWall | board.SouthWall | board.WestWall
+
+// kattisCoord returns the field coordinate of the cell in row r (0: top) and column c.
+func kattisCoord(r, c int) byte { return coord.Ctob(c, numField-1-r) }
+
+// blocked reports whether field f is a blocked field, a field with walls on all sides.
+func blocked(f *board.Field) bool { return f.Walls == allWalls }
+
+// ExportKattis returns the Kattis puzzle of position p with move limit limit. Robot 1
+// is the robot of the target color, the other robots follow in robot order. Only
+// boards whose walls all enclose blocked fields can be exported: the grid is the
+// smallest rectangle at the top left corner holding all fields that are not
+// blocked. Robots on blocked fields are left out. ErrUnsupported is returned for
+// other boards, for cosmic and silver targets and for robots on the target field.
+func ExportKattis(p *Position, limit int) ([]byte, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("interop: invalid move limit %d", limit)
+	}
+	ti := board.RobotIndex(p.Color)
+	if ti == -1 || p.Symbol == board.Cosmic {
+		return nil, fmt.Errorf("%w: target %s %s", ErrUnsupported, p.Symbol, p.Color)
+	}
+	target := -1
+	for c, f := range p.Board.Fields {
+		if f.Symbol == p.Symbol && f.Color == p.Color {
+			target = c
+		}
+	}
+	if target == -1 {
+		return nil, fmt.Errorf("interop: no target %s %s on board", p.Symbol, p.Color)
+	}
+
+	w, h := 0, 0
+	for c, f := range p.Board.Fields {
+		if x, y := coord.Btoc(byte(c)); !blocked(f) {
+			w, h = max(w, x+1), max(h, numField-y)
+		}
+	}
+	inGrid := func(x, y int) bool { return x >= 0 && x < w && y < numField && numField-1-y < h }
+	for c, f := range p.Board.Fields {
+		x, y := coord.Btoc(byte(c))
+		if !inGrid(x, y) || blocked(f) {
+			continue
+		}
+		for _, d := range board.Directions {
+			if f.Walls&d.Wall() == 0 {
+				continue
+			}
+			nx, ny := x, y
+			switch d {
+			case board.North:
+				ny++
+			case board.East:
+				nx++
+			case board.South:
+				ny--
+			case board.West:
+				nx--
+			}
+			if inGrid(nx, ny) && !blocked(p.Board.Field(nx, ny)) {
+				return nil, fmt.Errorf("%w: wall %s of field %d,%d", ErrUnsupported, d, x, y)
+			}
+		}
+	}
+
+	grid := make([][]byte, h)
+	for r := range grid {
+		grid[r] = make([]byte, w)
+		for c := range grid[r] {
+			if blocked(p.Board.Fields[kattisCoord(r, c)]) {
+				grid[r][c] = 'W'
+			} else {
+				grid[r][c] = '.'
+			}
+		}
+	}
+	tx, ty := coord.Btoc(byte(target))
+	grid[numField-1-ty][tx] = 'X'
+
+	order := []int{ti}
+	for i := range p.Robots {
+		if i != ti {
+			order = append(order, i)
+		}
+	}
+	n := 0
+	for _, i := range order {
+		c := p.Robots[i]
+		if blocked(p.Board.Fields[c]) {
+			if i == ti {
+				return nil, fmt.Errorf("%w: %s robot on blocked field", ErrUnsupported, p.Color)
+			}
+			continue
+		}
+		if int(c) == target {
+			return nil, fmt.Errorf("%w: robot on target field", ErrUnsupported)
+		}
+		x, y := coord.Btoc(c)
+		n++
+		grid[numField-1-y][x] = byte('0' + n)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%d %d %d %d\n", n, w, h, limit)
+	for _, row := range grid {
+		buf.Write(row)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// ImportKattis returns the position and the move limit of Kattis puzzle data. The
+// grid is placed at the top left corner of the board, fields outside of the grid
+// and 'W' cells become blocked fields. As the puzzle holds no target symbol, the
+// board gets a single target of symbol symbol on the 'X' cell. Robot 1 is the
+// yellow robot and the target has its color, robots 2 to 4 are the red, green and
+// blue robot. Robots not in the grid are placed on blocked fields, where they can
+// neither move nor block other robots.
+func ImportKattis(data []byte, symbol board.Symbol) (*Position, int, error) {
+	if symbol.Letter() == 0 || symbol == board.Cosmic {
+		return nil, 0, fmt.Errorf("interop: invalid target symbol %s", symbol)
+	}
+	lines := strings.Split(string(data), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " \t\r")
+	}
+	for len(lines) != 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil, 0, fmt.Errorf("interop: empty puzzle")
+	}
+	header := strings.Fields(lines[0])
+	if len(header) != 4 {
+		return nil, 0, fmt.Errorf("interop: header %q: 4 numbers expected", lines[0])
+	}
+	var v [4]int
+	for i, s := range header {
+		var err error
+		if v[i], err = strconv.Atoi(s); err != nil {
+			return nil, 0, fmt.Errorf("interop: header %q: invalid number %q", lines[0], s)
+		}
+	}
+	n, w, h, limit := v[0], v[1], v[2], v[3]
+	switch {
+	case n < 1 || n > board.NumRobot:
+		return nil, 0, fmt.Errorf("interop: invalid number of robots %d", n)
+	case w < 1 || w > numField || h < 1 || h > numField:
+		return nil, 0, fmt.Errorf("interop: invalid grid size %dx%d", w, h)
+	case limit < 0:
+		return nil, 0, fmt.Errorf("interop: invalid move limit %d", limit)
+	case len(lines) != h+1:
+		return nil, 0, fmt.Errorf("interop: %d grid lines - expected %d", len(lines)-1, h)
+	}
+
+	var fields [board.NumField]board.Field
+	for c := range fields {
+		fields[c].Walls = allWalls
+	}
+	p := &Position{Symbol: symbol, Color: board.RobotColors[0]}
+	found, target := [board.NumRobot]bool{}, false
+	for r, line := range lines[1:] {
+		if len(line) != w {
+			return nil, 0, fmt.Errorf("interop: grid line %d: %d cells - expected %d", r+1, len(line), w)
+		}
+		for col, ch := range []byte(line) {
+			c := kattisCoord(r, col)
+			f := &fields[c]
+			switch {
+			case ch == 'W':
+				continue
+			case ch == 'X':
+				if target {
+					return nil, 0, fmt.Errorf("interop: grid line %d: duplicate target", r+1)
+				}
+				target = true
+				f.Symbol, f.Color = p.Symbol, p.Color
+			case ch >= '1' && int(ch-'0') <= n:
+				i := int(ch - '1')
+				if found[i] {
+					return nil, 0, fmt.Errorf("interop: grid line %d: duplicate robot %c", r+1, ch)
+				}
+				found[i] = true
+				p.Robots[i] = c
+			case ch != '.':
+				return nil, 0, fmt.Errorf("interop: grid line %d: invalid cell %q", r+1, ch)
+			}
+			f.Walls = 0
+		}
+	}
+	if !target {
+		return nil, 0, fmt.Errorf("interop: no target")
+	}
+	for i := 0; i < n; i++ {
+		if !found[i] {
+			return nil, 0, fmt.Errorf("interop: robot %d missing", i+1)
+		}
+	}
+
+	// robots not in the grid on blocked fields, starting at the top right corner
+	c := board.NumField - 1
+	for i := n; i < board.NumRobot; i++ {
+		for c >= 0 && fields[c].Walls != allWalls {
+			c--
+		}
+		if c < 0 {
+			return nil, 0, fmt.Errorf("%w: no blocked field for robot %d", ErrUnsupported, i+1)
+		}
+		p.Robots[i] = byte(c)
+		c--
+	}
+
+	var err error
+	if p.Board, err = board.NewFromFields(&fields); err != nil {
+		return nil, 0, err
+	}
+	return p, limit, nil
+}
@@ -0,0 +1,340 @@
+package record
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/go-ricrob/game/board"
+)
+
+// SyntaxError is the error returned for malformed records.
+type SyntaxError struct {
+	Line int // 1-based line number
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string { return fmt.Sprintf("record: line %d: %s", e.Line, e.Msg) }
+
+// Reader reads a game record round by round.
+type Reader struct {
+	s       *bufio.Scanner
+	lineNo  int
+	pending string // line read ahead
+	h       *Header
+	robots  board.Robots
+	n       int // number of rounds read
+}
+
+func (r *Reader) errorf(format string, args ...any) error {
+	return &SyntaxError{Line: r.lineNo, Msg: fmt.Sprintf(format, args...)}
+}
+
+// next returns the next line which is neither blank nor a comment.
+func (r *Reader) next() (string, bool, error) {
+	if line := r.pending; line != "" {
+		r.pending = ""
+		return line, true, nil
+	}
+	for r.s.Scan() {
+		r.lineNo++
+		line := strings.TrimSpace(r.s.Text())
+		if line != "" && line[0] != '#' {
+			return line, true, nil
+		}
+	}
+	return "", false, r.s.Err()
+}
+
+// NewReader returns a reader reading from rd and reads the record header.
+func NewReader(rd io.Reader) (*Reader, error) {
+	r := &Reader{s: bufio.NewScanner(rd), h: &Header{}}
+	line, ok, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, r.errorf("empty record")
+	}
+	keyword, version, _ := strings.Cut(line, " ")
+	if keyword != magic {
+		return nil, r.errorf("%s expected", magic)
+	}
+	if version != strconv.Itoa(Version) {
+		return nil, r.errorf("unsupported version %q", version)
+	}
+	if err := r.readHeader(); err != nil {
+		return nil, err
+	}
+	r.robots = r.h.Robots
+	return r, nil
+}
+
+// Header returns the record header.
+func (r *Reader) Header() *Header { return r.h }
+
+func (r *Reader) readHeader() error {
+	h := r.h
+	seen := map[string]bool{}
+	for {
+		line, ok, err := r.next()
+		if err != nil {
+			return err
+		}
+		keyword, args, _ := strings.Cut(line, " ")
+		if !ok || keyword == "Round" {
+			r.pending = line
+			break
+		}
+		if seen[keyword] && keyword != "Tag" {
+			return r.errorf("duplicate %s", keyword)
+		}
+		seen[keyword] = true
+
+		switch keyword {
+		case "Tag":
+			if h.Tags == nil {
+				h.Tags = map[string]string{}
+			}
+			strs, err := r.quoted(args)
+			if err != nil {
+				return err
+			}
+			if len(strs) != 2 {
+				return r.errorf("tag name and value expected")
+			}
+			h.Tags[strs[0]] = strs[1]
+		case "Tiles":
+			if h.Board != nil {
+				return r.errorf("duplicate board")
+			}
+			var tileIDs [board.NumTile]string
+			ids := strings.Fields(args)
+			if len(ids) != len(tileIDs) {
+				return r.errorf("%d tiles expected", len(tileIDs))
+			}
+			copy(tileIDs[:], ids)
+			if h.Board, err = board.NewFromTiles(tileIDs); err != nil {
+				return r.errorf("%s", err)
+			}
+		case "Board":
+			if h.Board != nil {
+				return r.errorf("duplicate board")
+			}
+			data, err := base64.StdEncoding.DecodeString(args)
+			if err != nil {
+				return r.errorf("invalid board encoding: %s", err)
+			}
+			b := &board.Board{}
+			if err := b.UnmarshalBinary(data); err != nil {
+				return r.errorf("%s", err)
+			}
+			h.Board = b
+		case "Players":
+			players, err := r.quoted(args)
+			if err != nil {
+				return err
+			}
+			h.Players = players
+		case "Robots":
+			coords := strings.Fields(args)
+			if len(coords) != board.NumRobot {
+				return r.errorf("%d robot positions expected", board.NumRobot)
+			}
+			for i, s := range coords {
+				c, ok := parseCoord(s)
+				if !ok {
+					return r.errorf("invalid robot position %q", s)
+				}
+				h.Robots[i] = c
+			}
+			if err := checkRobots(h.Robots); err != nil {
+				return r.errorf("%s", err)
+			}
+		default:
+			return r.errorf("unexpected %q", keyword)
+		}
+	}
+	if !seen["Robots"] {
+		return r.errorf("missing robot positions")
+	}
+	if err := checkHeader(h); err != nil {
+		return r.errorf("%s", err)
+	}
+	return nil
+}
+
+// quoted parses the blank separated quoted strings s.
+func (r *Reader) quoted(s string) ([]string, error) {
+	var strs []string
+	for s != "" {
+		q, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return nil, r.errorf("quoted string expected: %s", s)
+		}
+		str, _ := strconv.Unquote(q)
+		strs = append(strs, str)
+		s = s[len(q):]
+		if s != "" {
+			if s[0] != ' ' {
+				return nil, r.errorf("blank expected after %s", q)
+			}
+			s = strings.TrimLeft(s, " ")
+		}
+	}
+	return strs, nil
+}
+
+// quotedArg parses the quoted string at the start of s and returns it and the
+// remaining arguments.
+func (r *Reader) quotedArg(s string) (string, string, error) {
+	q, err := strconv.QuotedPrefix(s)
+	if err != nil {
+		return "", "", r.errorf("quoted string expected: %s", s)
+	}
+	str, _ := strconv.Unquote(q)
+	rest := s[len(q):]
+	if rest != "" && rest[0] != ' ' {
+		return "", "", r.errorf("blank expected after %s", q)
+	}
+	return str, strings.TrimLeft(rest, " "), nil
+}
+
+// Next reads the next round. It returns io.EOF at the end of the record.
+func (r *Reader) Next() (*Round, error) {
+	line, ok, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, io.EOF
+	}
+	roundLine := r.lineNo
+	keyword, args, _ := strings.Cut(line, " ")
+	if keyword != "Round" {
+		return nil, r.errorf("Round expected")
+	}
+	round, err := r.parseRoundLine(args)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		line, ok, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		keyword, args, _ := strings.Cut(line, " ")
+		if !ok || keyword == "Round" {
+			r.pending = line
+			break
+		}
+		if err := r.parseRoundEntry(round, keyword, args); err != nil {
+			return nil, err
+		}
+	}
+
+	robots, err := checkRound(r.h, r.robots, round)
+	if err != nil {
+		return nil, &SyntaxError{Line: roundLine, Msg: fmt.Sprintf("round %d: %s", r.n+1, err)}
+	}
+	r.n++
+	r.robots = robots
+	return round, nil
+}
+
+// parseRoundLine parses the arguments of a Round line.
+func (r *Reader) parseRoundLine(args string) (*Round, error) {
+	fields := strings.Fields(args)
+	if len(fields) < 2 || len(fields) > 3 {
+		return nil, r.errorf("round number and target expected")
+	}
+	if n, err := strconv.Atoi(fields[0]); err != nil || n != r.n+1 {
+		return nil, r.errorf("round %d expected", r.n+1)
+	}
+	round := &Round{}
+	symbol, ok := parseSymbol(fields[1])
+	if !ok {
+		return nil, r.errorf("invalid symbol %q", fields[1])
+	}
+	round.Symbol = symbol
+	if len(fields) == 3 {
+		if round.Color, ok = parseColor(fields[2]); !ok {
+			return nil, r.errorf("invalid color %q", fields[2])
+		}
+	}
+	if (symbol == board.Cosmic) != (round.Color == 0) {
+		return nil, r.errorf("invalid target %s", formatTarget(round.Symbol, round.Color))
+	}
+	return round, nil
+}
+
+// parseRoundEntry parses a line of a round.
+func (r *Reader) parseRoundEntry(round *Round, keyword, args string) error {
+	switch keyword {
+	case "Bid", "Score":
+		player, rest, err := r.quotedArg(args)
+		if err != nil {
+			return err
+		}
+		n, err := strconv.Atoi(rest)
+		if err != nil {
+			return r.errorf("invalid number %q", rest)
+		}
+		if keyword == "Bid" {
+			round.Bids = append(round.Bids, Bid{Player: player, Moves: n})
+			return nil
+		}
+		if _, ok := round.Scores[player]; ok {
+			return r.errorf("duplicate score of %q", player)
+		}
+		if round.Scores == nil {
+			round.Scores = map[string]int{}
+		}
+		round.Scores[player] = n
+	case "Solution":
+		if round.Winner != "" {
+			return r.errorf("duplicate solution")
+		}
+		player, rest, err := r.quotedArg(args)
+		if err != nil {
+			return err
+		}
+		if player == "" {
+			return r.errorf("empty winner")
+		}
+		moves, err := board.ParseMoves(rest)
+		if err != nil {
+			return r.errorf("%s", err)
+		}
+		if moves == nil {
+			moves = []board.Move{}
+		}
+		round.Winner, round.Solution = player, moves
+	default:
+		return r.errorf("unexpected %q", keyword)
+	}
+	return nil
+}
+
+// ReadGame reads a complete game record.
+func ReadGame(rd io.Reader) (*Game, error) {
+	r, err := NewReader(rd)
+	if err != nil {
+		return nil, err
+	}
+	g := &Game{Header: r.Header()}
+	for {
+		round, err := r.Next()
+		if err == io.EOF {
+			return g, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		g.Rounds = append(g.Rounds, round)
+	}
+}
@@ -0,0 +1,250 @@
+// Package record provides a file format for complete games, similar in spirit to
+// PGN for chess. A record is line oriented and human readable: each line starts
+// with a keyword followed by its arguments separated by blanks. Blank lines and
+// lines starting with '#' are ignored. Player names and tag values are Go quoted
+// strings, moves are written in move notation (see board.FormatMoves).
+//
+// The header describes the game:
+//
+//	RicrobRecord 1                   format version, always the first line
+//	Tag "Event" "Club night"         any number of tags
+//	Tiles A1F A2F A4F A3F            board tiles, top left, top right, bottom right, bottom left
+//	Board AQAAAAAAAAAAA...           or the base64 encoded binary board
+//	Players "Alice" "Bob"
+//	Robots 0,10 3,12 5,5 10,2        starting positions of the yellow, red, green and blue robot
+//
+// followed by the rounds:
+//
+//	Round 1 Star red                 round number and target (Round 2 Cosmic for the cosmic target)
+//	Bid "Bob" 7                      any number of bids, in the order they were made
+//	Bid "Alice" 5
+//	Solution "Alice" R↑ B→ R←        winner and winning solution, omitted if unsolved
+//	Score "Alice" 1                  scores of the players after the round
+//	Score "Bob" 0
+//
+// The robots stay where the winning solution leaves them, so the robot positions at
+// the start of a round follow from the starting positions and the solutions of the
+// preceding rounds. Solutions need to move a robot of the target color (any robot
+// for cosmic and silver targets) onto the target and must not exceed the winner's
+// bid.
+package record
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/coord"
+)
+
+// Version is the record format version.
+const Version = 1
+
+const magic = "RicrobRecord"
+
+// Header is the record header.
+type Header struct {
+	Tags    map[string]string
+	Board   *board.Board
+	Players []string
+	Robots  board.Robots // starting positions
+}
+
+// Bid is a player's bid.
+type Bid struct {
+	Player string
+	Moves  int
+}
+
+// Round is a game round.
+type Round struct {
+	Symbol   board.Symbol
+	Color    board.Color
+	Bids     []Bid
+	Winner   string         // empty if unsolved
+	Solution []board.Move   // winning solution
+	Scores   map[string]int // scores after the round
+}
+
+// Game is a complete game record.
+type Game struct {
+	Header *Header
+	Rounds []*Round
+}
+
+// Position returns the robot positions at the start of round n (1-based). The
+// positions after the last round are returned for n = len(g.Rounds)+1.
+func (g *Game) Position(n int) (board.Robots, error) {
+	if n < 1 || n > len(g.Rounds)+1 {
+		return board.Robots{}, fmt.Errorf("record: invalid round %d", n)
+	}
+	robots := g.Header.Robots
+	for i, r := range g.Rounds[:n-1] {
+		var err error
+		if robots, err = play(g.Header.Board, robots, r); err != nil {
+			return board.Robots{}, fmt.Errorf("record: round %d: %w", i+1, err)
+		}
+	}
+	return robots, nil
+}
+
+// play returns the robot positions after round r.
+func play(b *board.Board, robots board.Robots, r *Round) (board.Robots, error) {
+	for _, m := range r.Solution {
+		if board.RobotIndex(m.Robot) == -1 || m.Direction >= board.NumDirection {
+			return robots, fmt.Errorf("invalid move %s", m)
+		}
+		robots = b.Move(robots, m)
+	}
+	return robots, nil
+}
+
+// checkHeader checks header h.
+func checkHeader(h *Header) error {
+	if h.Board == nil {
+		return fmt.Errorf("missing board")
+	}
+	seen := map[string]bool{}
+	for _, p := range h.Players {
+		if p == "" {
+			return fmt.Errorf("empty player name")
+		}
+		if seen[p] {
+			return fmt.Errorf("duplicate player %q", p)
+		}
+		seen[p] = true
+	}
+	return checkRobots(h.Robots)
+}
+
+// checkRobots checks that no two robots are located on the same field.
+func checkRobots(robots board.Robots) error {
+	for i, c := range robots {
+		for j := 0; j < i; j++ {
+			if robots[j] == c {
+				return fmt.Errorf("robots %s and %s on the same field", board.RobotColors[j], board.RobotColors[i])
+			}
+		}
+	}
+	return nil
+}
+
+func isPlayer(h *Header, name string) bool {
+	for _, p := range h.Players {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTarget checks the target of round r.
+func checkTarget(h *Header, r *Round) error {
+	for _, f := range h.Board.Fields {
+		if f.Symbol != board.NoSymbol && f.Symbol == r.Symbol && f.Color == r.Color {
+			return nil
+		}
+	}
+	return fmt.Errorf("no target %s on board", formatTarget(r.Symbol, r.Color))
+}
+
+// checkRound checks round r played from robot positions robots and returns the robot
+// positions after the round.
+func checkRound(h *Header, robots board.Robots, r *Round) (board.Robots, error) {
+	if err := checkTarget(h, r); err != nil {
+		return robots, err
+	}
+	for _, bid := range r.Bids {
+		if !isPlayer(h, bid.Player) {
+			return robots, fmt.Errorf("bid of unknown player %q", bid.Player)
+		}
+		if bid.Moves < 0 {
+			return robots, fmt.Errorf("invalid bid %d", bid.Moves)
+		}
+	}
+	for p := range r.Scores {
+		if !isPlayer(h, p) {
+			return robots, fmt.Errorf("score of unknown player %q", p)
+		}
+	}
+	if r.Winner == "" {
+		if r.Solution != nil {
+			return robots, fmt.Errorf("solution without winner")
+		}
+		return robots, nil
+	}
+	if !isPlayer(h, r.Winner) {
+		return robots, fmt.Errorf("unknown winner %q", r.Winner)
+	}
+	after, err := play(h.Board, robots, r)
+	if err != nil {
+		return robots, err
+	}
+	if bid, ok := lowestBid(r.Bids, r.Winner); ok && len(r.Solution) > bid {
+		return robots, fmt.Errorf("solution of %d moves exceeds bid of %d moves", len(r.Solution), bid)
+	}
+	target := h.Board.TargetCoord(r.Symbol, r.Color)
+	if i := board.RobotIndex(r.Color); i != -1 && r.Symbol != board.Cosmic {
+		if after[i] != target {
+			return robots, fmt.Errorf("solution does not move the %s robot to the target", r.Color)
+		}
+	} else if !after.Occupied(target) {
+		return robots, fmt.Errorf("solution does not move a robot to the target")
+	}
+	return after, nil
+}
+
+// lowestBid returns the lowest bid of player.
+func lowestBid(bids []Bid, player string) (int, bool) {
+	n, ok := 0, false
+	for _, bid := range bids {
+		if bid.Player == player && (!ok || bid.Moves < n) {
+			n, ok = bid.Moves, true
+		}
+	}
+	return n, ok
+}
+
+func formatTarget(symbol board.Symbol, color board.Color) string {
+	if color == 0 {
+		return symbol.String()
+	}
+	return symbol.String() + " " + color.String()
+}
+
+func parseSymbol(s string) (board.Symbol, bool) {
+	for _, symbol := range board.Symbols {
+		if symbol.String() == s {
+			return symbol, true
+		}
+	}
+	return board.NoSymbol, false
+}
+
+func parseColor(s string) (board.Color, bool) {
+	for _, color := range []board.Color{board.Yellow, board.Red, board.Green, board.Blue, board.Silver} {
+		if color.String() == s {
+			return color, true
+		}
+	}
+	return 0, false
+}
+
+func formatCoord(c byte) string {
+	x, y := coord.Btoc(c)
+	return strconv.Itoa(x) + "," + strconv.Itoa(y)
+}
+
+func parseCoord(s string) (byte, bool) {
+	xs, ys, ok := strings.Cut(s, ",")
+	if !ok {
+		return 0, false
+	}
+	x, err1 := strconv.Atoi(xs)
+	y, err2 := strconv.Atoi(ys)
+	if err1 != nil || err2 != nil || x < 0 || x > 15 || y < 0 || y > 15 {
+		return 0, false
+	}
+	return coord.Ctob(x, y), true
+}
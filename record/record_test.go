@@ -0,0 +1,231 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/internal/testutil"
+	"github.com/go-ricrob/game/solver"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// testGame returns a game of three rounds, the winning solutions found by the solver.
+func testGame(t *testing.T, b *board.Board) *Game {
+	t.Helper()
+	g := &Game{Header: &Header{
+		Tags:    map[string]string{"Event": "Club night", "Date": "2024-03-01"},
+		Board:   b,
+		Players: []string{"Alice", "Bob"},
+		Robots:  testutil.Robots,
+	}}
+	targets := []struct {
+		symbol board.Symbol
+		color  board.Color
+	}{
+		{board.Star, board.Red},
+		{board.Cosmic, 0},
+		{board.Moon, board.Green},
+	}
+	robots := testutil.Robots
+	scores := map[string]int{"Alice": 0, "Bob": 0}
+	for i, target := range targets {
+		solutions, err := solver.Solve(context.Background(), b, robots, target.symbol, target.color)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sol := solutions[0]
+		winner, loser := "Alice", "Bob"
+		if i%2 == 1 {
+			winner, loser = loser, winner
+		}
+		scores[winner]++
+		g.Rounds = append(g.Rounds, &Round{
+			Symbol:   target.symbol,
+			Color:    target.color,
+			Bids:     []Bid{{Player: loser, Moves: len(sol) + 2}, {Player: winner, Moves: len(sol)}},
+			Winner:   winner,
+			Solution: sol,
+			Scores:   map[string]int{"Alice": scores["Alice"], "Bob": scores["Bob"]},
+		})
+		for _, m := range sol {
+			robots = b.Move(robots, m)
+		}
+	}
+	// unsolved round
+	g.Rounds = append(g.Rounds, &Round{Symbol: board.Pyramid, Color: board.Blue, Scores: map[string]int{"Alice": scores["Alice"], "Bob": scores["Bob"]}})
+	return g
+}
+
+func TestRecord(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	g := testGame(t, b)
+
+	var buf bytes.Buffer
+	if err := WriteGame(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", "game.txt")
+	if *update {
+		if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("got\n%s\nwant\n%s", buf.Bytes(), want)
+	}
+
+	got, err := ReadGame(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Header.Board.ID() != b.ID() {
+		t.Fatal("board differs")
+	}
+	got.Header.Board = b
+	if !reflect.DeepEqual(got, g) {
+		t.Fatalf("got %+v - expected %+v", got, g)
+	}
+
+	// replay
+	robots := testutil.Robots
+	for n := 1; n <= len(g.Rounds)+1; n++ {
+		pos, err := got.Position(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pos != robots {
+			t.Fatalf("round %d: got positions %v - expected %v", n, pos, robots)
+		}
+		if n <= len(g.Rounds) {
+			for _, m := range g.Rounds[n-1].Solution {
+				robots = b.Move(robots, m)
+			}
+		}
+	}
+	if _, err := got.Position(len(g.Rounds) + 2); err == nil {
+		t.Fatal("invalid round: error expected")
+	}
+
+	// invalid moves of a game built in code
+	for _, m := range []board.Move{{Robot: board.Silver, Direction: board.North}, {Robot: board.Red, Direction: board.NumDirection}} {
+		got.Rounds[1].Solution = []board.Move{m}
+		if _, err := got.Position(3); err == nil {
+			t.Fatalf("invalid move %s: error expected", m)
+		}
+	}
+}
+
+func TestRecordEditedBoard(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	fields := [board.NumField]board.Field{}
+	for c, f := range b.Fields {
+		fields[c] = *f
+	}
+	fields[0x33].Walls |= board.NorthWall
+	eb, err := board.NewFromFields(&fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &Game{Header: &Header{Board: eb, Robots: testutil.Robots}}
+	var buf bytes.Buffer
+	if err := WriteGame(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "\nBoard ") {
+		t.Fatalf("binary board expected:\n%s", buf.String())
+	}
+	got, err := ReadGame(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Header.Board.ID() != eb.ID() {
+		t.Fatal("board differs")
+	}
+}
+
+func TestRecordErrors(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	var buf bytes.Buffer
+	if err := WriteGame(&buf, testGame(t, b)); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	find := func(prefix string) int {
+		for i, l := range lines {
+			if strings.HasPrefix(l, prefix) {
+				return i
+			}
+		}
+		t.Fatalf("no line %s", prefix)
+		return -1
+	}
+	// replace returns the record with line i replaced by s.
+	replace := func(i int, s string) string {
+		ls := append([]string(nil), lines...)
+		ls[i] = s
+		return strings.Join(ls, "\n")
+	}
+	solution := find("Solution")
+
+	tests := []struct {
+		name   string
+		record string
+		line   int
+	}{
+		{"empty", "", 0},
+		{"magic", replace(0, "PGN 1"), 1},
+		{"version", replace(0, "RicrobRecord 2"), 1},
+		{"tiles", replace(find("Tiles"), "Tiles A1F A2F"), find("Tiles") + 1},
+		{"tile id", replace(find("Tiles"), "Tiles A1F A2F A3F X"), find("Tiles") + 1},
+		{"robots", replace(find("Robots"), "Robots 0,10 3,12 5,5 10,16"), find("Robots") + 1},
+		{"same field", replace(find("Robots"), "Robots 0,10 3,12 5,5 0,10"), find("Robots") + 1},
+		{"players", replace(find("Players"), `Players "Alice" Bob`), find("Players") + 1},
+		{"keyword", replace(find("Players"), `Player "Alice"`), find("Players") + 1},
+		{"round number", replace(find("Round 1"), "Round 2 Star red"), find("Round 1") + 1},
+		{"target", replace(find("Round 1"), "Round 1 Star"), find("Round 1") + 1},
+		{"bid", replace(find("Bid"), `Bid "Alice" many`), find("Bid") + 1},
+		{"notation", replace(solution, `Solution "Alice" R↑ X→`), solution + 1},
+		{"unknown player", replace(solution, strings.Replace(lines[solution], "Alice", "Carol", 1)), find("Round 1") + 1},
+		{"wrong solution", replace(solution, `Solution "Alice" R↑`), find("Round 1") + 1},
+	}
+	for _, test := range tests {
+		_, err := ReadGame(strings.NewReader(test.record))
+		var serr *SyntaxError
+		if !errors.As(err, &serr) {
+			t.Fatalf("%s: syntax error expected - got %v", test.name, err)
+		}
+		if serr.Line != test.line {
+			t.Fatalf("%s: got line %d - expected %d (%s)", test.name, serr.Line, test.line, err)
+		}
+	}
+
+	w, err := NewWriter(&bytes.Buffer{}, &Header{Board: b, Players: []string{"Alice"}, Robots: testutil.Robots})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, r := range map[string]*Round{
+		"no target":      {Symbol: board.Star, Color: board.Silver},
+		"unknown player": {Symbol: board.Star, Color: board.Red, Bids: []Bid{{Player: "Bob", Moves: 3}}},
+		"exceeds bid":    {Symbol: board.Star, Color: board.Red, Bids: []Bid{{Player: "Alice", Moves: 0}}, Winner: "Alice", Solution: []board.Move{{Robot: board.Red, Direction: board.North}}},
+	} {
+		if err := w.WriteRound(r); err == nil {
+			t.Fatalf("%s: error expected", name)
+		}
+	}
+	if _, err := NewWriter(&bytes.Buffer{}, &Header{Board: b, Players: []string{"Alice", "Alice"}}); err == nil {
+		t.Fatal("duplicate player: error expected")
+	}
+}
@@ -0,0 +1,107 @@
+package record
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-ricrob/game/board"
+)
+
+// Writer writes a game record. Rounds are checked before they are written, so a
+// written record can always be read.
+type Writer struct {
+	w      *bufio.Writer
+	h      *Header
+	robots board.Robots
+	n      int // number of rounds written
+}
+
+// NewWriter returns a writer writing to w and writes header h.
+func NewWriter(w io.Writer, h *Header) (*Writer, error) {
+	if err := checkHeader(h); err != nil {
+		return nil, fmt.Errorf("record: %w", err)
+	}
+	wr := &Writer{w: bufio.NewWriter(w), h: h, robots: h.Robots}
+	fmt.Fprintf(wr.w, "%s %d\n", magic, Version)
+
+	keys := make([]string, 0, len(h.Tags))
+	for k := range h.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(wr.w, "Tag %s %s\n", strconv.Quote(k), strconv.Quote(h.Tags[k]))
+	}
+
+	if tileIDs, ok := h.Board.TileIDs(); ok {
+		fmt.Fprintf(wr.w, "Tiles %s\n", strings.Join(tileIDs[:], " "))
+	} else {
+		data, err := h.Board.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(wr.w, "Board %s\n", base64.StdEncoding.EncodeToString(data))
+	}
+
+	if len(h.Players) != 0 {
+		wr.w.WriteString("Players")
+		for _, p := range h.Players {
+			wr.w.WriteString(" " + strconv.Quote(p))
+		}
+		wr.w.WriteByte('\n')
+	}
+
+	wr.w.WriteString("Robots")
+	for _, c := range h.Robots {
+		wr.w.WriteString(" " + formatCoord(c))
+	}
+	wr.w.WriteByte('\n')
+	return wr, wr.w.Flush()
+}
+
+// WriteRound writes round r.
+func (w *Writer) WriteRound(r *Round) error {
+	robots, err := checkRound(w.h, w.robots, r)
+	if err != nil {
+		return fmt.Errorf("record: round %d: %w", w.n+1, err)
+	}
+	w.n++
+	w.robots = robots
+
+	fmt.Fprintf(w.w, "\nRound %d %s\n", w.n, formatTarget(r.Symbol, r.Color))
+	for _, bid := range r.Bids {
+		fmt.Fprintf(w.w, "Bid %s %d\n", strconv.Quote(bid.Player), bid.Moves)
+	}
+	if r.Winner != "" {
+		fmt.Fprintf(w.w, "Solution %s", strconv.Quote(r.Winner))
+		if len(r.Solution) != 0 {
+			fmt.Fprintf(w.w, " %s", board.FormatMoves(r.Solution))
+		}
+		w.w.WriteByte('\n')
+	}
+	for _, p := range w.h.Players {
+		if score, ok := r.Scores[p]; ok {
+			fmt.Fprintf(w.w, "Score %s %d\n", strconv.Quote(p), score)
+		}
+	}
+	return w.w.Flush()
+}
+
+// WriteGame writes game g to w.
+func WriteGame(w io.Writer, g *Game) error {
+	wr, err := NewWriter(w, g.Header)
+	if err != nil {
+		return err
+	}
+	for _, r := range g.Rounds {
+		if err := wr.WriteRound(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
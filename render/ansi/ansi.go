@@ -0,0 +1,260 @@
+// Package ansi renders boards for terminals. Walls are drawn with Unicode box
+// drawing characters, robots and targets are shown in their colors using ANSI
+// escape sequences.
+//
+// Each field is a cell three characters wide:
+//
+//	character 1: robot   ● in the robot color, Y R G B in monochrome
+//	character 2: target  ▲ pyramid, ★ star, ☾ moon, ♄ saturn, ✦ cosmic in the target color
+//	character 3: color   monochrome only: y yellow, r red, g green, b blue, s silver
+//
+// The highlighted target is shown in reverse video, in monochrome its color letter
+// is upper case (cosmic: '*'). Corners without walls are marked by '·', the center
+// block is filled with '░'.
+package ansi
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"unicode"
+
+	"github.com/go-ricrob/game/board"
+)
+
+const numField = 16
+
+// Mode is the color mode of the output.
+type Mode byte
+
+// Mode constants.
+const (
+	Auto       Mode = iota // colors if UseColor reports true for the writer
+	Colored                // always use colors
+	Monochrome             // never use colors
+)
+
+// Options are the rendering options.
+type Options struct {
+	Mode   Mode
+	Robots *board.Robots // robot positions, nil: no robots
+	Symbol board.Symbol  // highlighted target symbol, NoSymbol: no target
+	Color  board.Color   // highlighted target color
+}
+
+// UseColor reports whether colors should be used for output to w: w needs to be a
+// terminal and the environment variable NO_COLOR needs to be unset or empty (see
+// https://no-color.org).
+func UseColor(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// SGR (select graphic rendition) sequences.
+const (
+	sgrReset   = "\x1b[0m"
+	sgrDim     = "\x1b[2m"
+	sgrReverse = "\x1b[7m"
+)
+
+var (
+	colorSGRs = map[board.Color]string{
+		0:            "\x1b[35m", // cosmic: magenta
+		board.Yellow: "\x1b[33m",
+		board.Red:    "\x1b[31m",
+		board.Green:  "\x1b[32m",
+		board.Blue:   "\x1b[34m",
+		board.Silver: "\x1b[37m",
+	}
+	robotSGRs = [board.NumRobot]string{"\x1b[1;93m", "\x1b[1;91m", "\x1b[1;92m", "\x1b[1;94m"}
+
+	symbolRunes = map[board.Symbol]rune{board.Pyramid: '▲', board.Star: '★', board.Moon: '☾', board.Saturn: '♄', board.Cosmic: '✦'}
+)
+
+// corners are the box drawing characters of a corner indexed by the walls meeting
+// there: bit 0 up, bit 1 right, bit 2 down, bit 3 left.
+var corners = [16]rune{'·', '╵', '╶', '└', '╷', '│', '┌', '├', '╴', '┘', '─', '┴', '┐', '┤', '┬', '┼'}
+
+const (
+	up    = 1
+	right = 2
+	down  = 4
+	left  = 8
+)
+
+func isCenter(x, y int) bool { return x >= 7 && x <= 8 && y >= 7 && y <= 8 }
+
+type renderer struct {
+	w     *bufio.Writer
+	b     *board.Board
+	opts  *Options
+	color bool
+}
+
+// Render writes the terminal representation of board b to w.
+func Render(w io.Writer, b *board.Board, opts *Options) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+	r := &renderer{w: bufio.NewWriter(w), b: b, opts: opts}
+	switch opts.Mode {
+	case Auto:
+		r.color = UseColor(w)
+	case Colored:
+		r.color = true
+	}
+
+	for y := numField; y >= 0; y-- {
+		r.wallLine(y)
+		if y > 0 {
+			r.cellLine(y - 1)
+		}
+	}
+	return r.w.Flush()
+}
+
+// wall reports whether field x,y has wall w. Fields outside of the board have no walls.
+func (r *renderer) wall(x, y int, w board.Wall) bool {
+	if x < 0 || x >= numField || y < 0 || y >= numField {
+		return false
+	}
+	return r.b.Field(x, y).Walls&w != 0
+}
+
+// hwall reports whether there is a wall south of field x,y.
+func (r *renderer) hwall(x, y int) bool {
+	return r.wall(x, y, board.SouthWall) || r.wall(x, y-1, board.NorthWall)
+}
+
+// vwall reports whether there is a wall west of field x,y.
+func (r *renderer) vwall(x, y int) bool {
+	return r.wall(x, y, board.WestWall) || r.wall(x-1, y, board.EastWall)
+}
+
+// wallLine writes the line of the walls south of row y.
+func (r *renderer) wallLine(y int) {
+	for x := 0; x <= numField; x++ {
+		mask := 0
+		if r.vwall(x, y) {
+			mask |= up
+		}
+		if r.hwall(x, y) {
+			mask |= right
+		}
+		if r.vwall(x, y-1) {
+			mask |= down
+		}
+		if r.hwall(x-1, y) {
+			mask |= left
+		}
+		switch {
+		case isCenter(x, y) && isCenter(x-1, y-1):
+			r.dim("░")
+		case mask == 0:
+			r.dim(string(corners[0]))
+		default:
+			r.w.WriteRune(corners[mask])
+		}
+		if x < numField {
+			switch {
+			case isCenter(x, y) && isCenter(x, y-1):
+				r.dim("░░░")
+			case mask&right != 0:
+				r.w.WriteString("───")
+			default:
+				r.w.WriteString("   ")
+			}
+		}
+	}
+	r.w.WriteByte('\n')
+}
+
+// cellLine writes the line of the cells of row y.
+func (r *renderer) cellLine(y int) {
+	for x := 0; x <= numField; x++ {
+		switch {
+		case isCenter(x, y) && isCenter(x-1, y):
+			r.dim("░")
+		case r.vwall(x, y):
+			r.w.WriteRune('│')
+		default:
+			r.w.WriteByte(' ')
+		}
+		if x < numField {
+			r.cell(x, y)
+		}
+	}
+	r.w.WriteByte('\n')
+}
+
+// dim writes s in dim intensity.
+func (r *renderer) dim(s string) {
+	if r.color {
+		r.w.WriteString(sgrDim + s + sgrReset)
+	} else {
+		r.w.WriteString(s)
+	}
+}
+
+// cell writes the cell of field x,y.
+func (r *renderer) cell(x, y int) {
+	if isCenter(x, y) {
+		r.dim("░░░")
+		return
+	}
+	c := byte(x)<<4 | byte(y)
+	robot := -1
+	if r.opts.Robots != nil {
+		for i, rc := range r.opts.Robots {
+			if rc == c {
+				robot = i
+			}
+		}
+	}
+	f := r.b.Fields[c]
+	highlight := f.Symbol != board.NoSymbol && f.Symbol == r.opts.Symbol && f.Color == r.opts.Color
+
+	if !r.color {
+		cell := []rune("   ")
+		if robot != -1 {
+			cell[0] = rune(board.RobotLetters[robot])
+		}
+		if f.Symbol != board.NoSymbol {
+			cell[1] = symbolRunes[f.Symbol]
+			if l := f.Color.Letter(); l != 0 {
+				cell[2] = rune(l)
+			}
+			if highlight {
+				if f.Symbol == board.Cosmic {
+					cell[2] = '*'
+				} else {
+					cell[2] = unicode.ToUpper(cell[2])
+				}
+			}
+		}
+		r.w.WriteString(string(cell))
+		return
+	}
+
+	if robot != -1 {
+		r.w.WriteString(robotSGRs[robot] + "●" + sgrReset)
+	} else {
+		r.w.WriteByte(' ')
+	}
+	if f.Symbol == board.NoSymbol {
+		r.w.WriteString("  ")
+		return
+	}
+	sgr := colorSGRs[f.Color]
+	if highlight {
+		sgr += sgrReverse
+	}
+	r.w.WriteString(sgr + string(symbolRunes[f.Symbol]) + sgrReset + " ")
+}
@@ -0,0 +1,63 @@
+package ansi
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/internal/testutil"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestRender(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	tests := []struct {
+		name string
+		opts *Options
+	}{
+		{"board", nil},
+		{"mono", &Options{Mode: Monochrome, Robots: &testutil.Robots, Symbol: board.Star, Color: board.Red}},
+		{"color", &Options{Mode: Colored, Robots: &testutil.Robots, Symbol: board.Star, Color: board.Red}},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := Render(&buf, b, test.opts); err != nil {
+			t.Fatal(err)
+		}
+		golden := filepath.Join("testdata", test.name+".txt")
+		if *update {
+			if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Fatalf("%s: got\n%s\nwant\n%s", test.name, buf.Bytes(), want)
+		}
+	}
+}
+
+func TestUseColor(t *testing.T) {
+	if UseColor(&bytes.Buffer{}) {
+		t.Fatal("buffer: no colors expected")
+	}
+	f, err := os.CreateTemp(t.TempDir(), "ansi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if UseColor(f) {
+		t.Fatal("regular file: no colors expected")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if UseColor(os.Stdout) {
+		t.Fatal("NO_COLOR set: no colors expected")
+	}
+}
@@ -0,0 +1,368 @@
+// Package raster renders boards as raster images: a PNG image of a position and an
+// animated GIF image playing a solution, the moving robot sliding from field to
+// field. Only the standard image packages are used and no anti-aliasing is
+// applied, so the output is the same on all platforms.
+package raster
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/coord"
+)
+
+const numField = 16
+
+// Style defines the appearance of a rendered board. Nil colors and a nil Colors map
+// are taken from the default style.
+type Style struct {
+	CellSize   int                         // width and height of a field in pixels
+	Colors     map[board.Color]color.Color // colors of robots and targets, color 0: cosmic target
+	Background color.Color                 // field color
+	Grid       color.Color                 // color of the lines between fields
+	Wall       color.Color                 // wall and robot outline color
+	Center     color.Color                 // color of the center block
+	Highlight  color.Color                 // color of the highlighted target frame
+}
+
+// DefaultStyle returns the default style.
+func DefaultStyle() *Style {
+	return &Style{
+		CellSize: 32,
+		Colors: map[board.Color]color.Color{
+			board.Yellow: color.RGBA{0xf2, 0xc2, 0x00, 0xff},
+			board.Red:    color.RGBA{0xd6, 0x28, 0x28, 0xff},
+			board.Green:  color.RGBA{0x2a, 0x9d, 0x3f, 0xff},
+			board.Blue:   color.RGBA{0x1f, 0x5f, 0xbf, 0xff},
+			board.Silver: color.RGBA{0xa0, 0xa0, 0xa0, 0xff},
+			0:            color.RGBA{0x7b, 0x3f, 0xa0, 0xff},
+		},
+		Background: color.RGBA{0xf4, 0xf1, 0xe8, 0xff},
+		Grid:       color.RGBA{0xd8, 0xd2, 0xc0, 0xff},
+		Wall:       color.RGBA{0x33, 0x33, 0x33, 0xff},
+		Center:     color.RGBA{0x55, 0x55, 0x55, 0xff},
+		Highlight:  color.Black,
+	}
+}
+
+// withDefaults returns a copy of the style with nil colors and a nil Colors map
+// replaced by the ones of the default style.
+func (s *Style) withDefaults() *Style {
+	d, c := DefaultStyle(), *s
+	if c.Colors == nil {
+		c.Colors = d.Colors
+	}
+	for _, p := range []struct{ col, def *color.Color }{
+		{&c.Background, &d.Background},
+		{&c.Grid, &d.Grid},
+		{&c.Wall, &d.Wall},
+		{&c.Center, &d.Center},
+		{&c.Highlight, &d.Highlight},
+	} {
+		if *p.col == nil {
+			*p.col = *p.def
+		}
+	}
+	return &c
+}
+
+// color returns the color of robots and targets of color c, the wall color if c is
+// not defined.
+func (s *Style) color(c board.Color) color.Color {
+	if col, ok := s.Colors[c]; ok && col != nil {
+		return col
+	}
+	return s.Wall
+}
+
+// palette returns the colors of the style.
+func (s *Style) palette() color.Palette {
+	p := color.Palette{s.Background, s.Grid, s.Wall, s.Center, s.Highlight}
+	for _, c := range []board.Color{0, board.Yellow, board.Red, board.Green, board.Blue, board.Silver} {
+		p = append(p, s.color(c))
+	}
+	return p
+}
+
+// Options are the rendering options.
+type Options struct {
+	Style  *Style        // nil: default style
+	Robots *board.Robots // robot positions, nil: no robots
+	Symbol board.Symbol  // highlighted target symbol, NoSymbol: no target
+	Color  board.Color   // highlighted target color
+}
+
+// Animation defines the frame timing of an animated solution. Delays are given in
+// 100ths of a second.
+type Animation struct {
+	Frames     int // frames per move
+	FrameDelay int // delay of a frame of a moving robot
+	MoveDelay  int // delay after a move
+	FinalDelay int // delay of the start and the final position
+}
+
+// DefaultAnimation returns the default animation timing.
+func DefaultAnimation() *Animation {
+	return &Animation{Frames: 8, FrameDelay: 4, MoveDelay: 50, FinalDelay: 200}
+}
+
+type renderer struct {
+	b     *board.Board
+	style *Style
+	cell  int
+}
+
+func newRenderer(b *board.Board, opts *Options) (*renderer, *Options, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	style := DefaultStyle()
+	if opts.Style != nil {
+		if opts.Style.CellSize <= 0 {
+			return nil, nil, fmt.Errorf("raster: invalid cell size %d", opts.Style.CellSize)
+		}
+		style = opts.Style.withDefaults()
+	}
+	return &renderer{b: b, style: style, cell: style.CellSize}, opts, nil
+}
+
+// Render returns the image of board b.
+func Render(b *board.Board, opts *Options) (*image.RGBA, error) {
+	r, opts, err := newRenderer(b, opts)
+	if err != nil {
+		return nil, err
+	}
+	img := r.board(opts)
+	if opts.Robots != nil {
+		for i, c := range opts.Robots {
+			x, y := r.origin(c)
+			r.robot(img, x, y, board.RobotColors[i])
+		}
+	}
+	return img, nil
+}
+
+// RenderPNG writes the PNG image of board b to w.
+func RenderPNG(w io.Writer, b *board.Board, opts *Options) error {
+	img, err := Render(b, opts)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// RenderGIF writes an animated GIF image to w playing solution starting at the
+// robot positions of opts. anim nil selects the default animation timing.
+func RenderGIF(w io.Writer, b *board.Board, solution []board.Move, opts *Options, anim *Animation) error {
+	r, opts, err := newRenderer(b, opts)
+	if err != nil {
+		return err
+	}
+	if opts.Robots == nil {
+		return errors.New("raster: animation without robot positions")
+	}
+	if anim == nil {
+		anim = DefaultAnimation()
+	}
+	if anim.Frames <= 0 {
+		return fmt.Errorf("raster: invalid number of frames per move %d", anim.Frames)
+	}
+	for _, m := range solution {
+		if board.RobotIndex(m.Robot) == -1 || m.Direction >= board.NumDirection {
+			return fmt.Errorf("raster: invalid move %s", m)
+		}
+	}
+
+	base := r.board(opts)
+	palette := r.style.palette()
+	g := &gif.GIF{}
+	// frame adds a frame with robot i at pixel position x,y and all other robots on their fields.
+	frame := func(robots board.Robots, i, x, y, delay int) {
+		img := image.NewRGBA(base.Rect)
+		copy(img.Pix, base.Pix)
+		for j, c := range robots {
+			if j != i {
+				x, y := r.origin(c)
+				r.robot(img, x, y, board.RobotColors[j])
+			}
+		}
+		if i != -1 {
+			r.robot(img, x, y, board.RobotColors[i])
+		}
+		p := image.NewPaletted(img.Rect, palette)
+		draw.Draw(p, p.Rect, img, image.Point{}, draw.Src)
+		g.Image = append(g.Image, p)
+		g.Delay = append(g.Delay, delay)
+	}
+
+	robots := *opts.Robots
+	frame(robots, -1, 0, 0, anim.FinalDelay)
+	for k, m := range solution {
+		i := board.RobotIndex(m.Robot)
+		next := b.Move(robots, m)
+		x0, y0 := r.origin(robots[i])
+		x1, y1 := r.origin(next[i])
+		for f := 1; f <= anim.Frames; f++ {
+			delay := anim.FrameDelay
+			if f == anim.Frames {
+				delay = anim.MoveDelay
+				if k == len(solution)-1 {
+					delay = anim.FinalDelay
+				}
+			}
+			frame(robots, i, x0+(x1-x0)*f/anim.Frames, y0+(y1-y0)*f/anim.Frames, delay)
+		}
+		robots = next
+	}
+	return gif.EncodeAll(w, g)
+}
+
+// origin returns the pixel position of the top left corner of field c.
+func (r *renderer) origin(c byte) (int, int) {
+	x, y := coord.Btoc(c)
+	return x * r.cell, (numField - 1 - y) * r.cell
+}
+
+// board returns the image of the board without robots.
+func (r *renderer) board(opts *Options) *image.RGBA {
+	size := numField * r.cell
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Rect, image.NewUniform(r.style.Background), image.Point{}, draw.Src)
+	fill := func(rect image.Rectangle, c color.Color) {
+		draw.Draw(img, rect, image.NewUniform(c), image.Point{}, draw.Src)
+	}
+
+	for i := 1; i < numField; i++ {
+		p := i * r.cell
+		fill(image.Rect(p, 0, p+1, size), r.style.Grid)
+		fill(image.Rect(0, p, size, p+1), r.style.Grid)
+	}
+	fill(image.Rect(7*r.cell, 7*r.cell, 9*r.cell, 9*r.cell), r.style.Center)
+
+	for c, f := range r.b.Fields {
+		if f.Symbol == board.NoSymbol {
+			continue
+		}
+		x, y := r.origin(byte(c))
+		if f.Symbol == opts.Symbol && f.Color == opts.Color {
+			outline(img, image.Rect(x+1, y+1, x+r.cell, y+r.cell), r.style.Highlight)
+		}
+		r.glyph(img, x, y, f.Symbol, r.style.color(f.Color))
+	}
+
+	t := max(2, r.cell/10) // wall thickness
+	for c, f := range r.b.Fields {
+		x, y := r.origin(byte(c))
+		if f.Walls&board.NorthWall != 0 {
+			fill(image.Rect(x-t/2, y-t/2, x+r.cell+t-t/2, y+t-t/2), r.style.Wall)
+		}
+		if f.Walls&board.SouthWall != 0 {
+			fill(image.Rect(x-t/2, y+r.cell-t/2, x+r.cell+t-t/2, y+r.cell+t-t/2), r.style.Wall)
+		}
+		if f.Walls&board.WestWall != 0 {
+			fill(image.Rect(x-t/2, y-t/2, x+t-t/2, y+r.cell+t-t/2), r.style.Wall)
+		}
+		if f.Walls&board.EastWall != 0 {
+			fill(image.Rect(x+r.cell-t/2, y-t/2, x+r.cell+t-t/2, y+r.cell+t-t/2), r.style.Wall)
+		}
+	}
+	return img
+}
+
+// outline draws the one pixel wide outline of rectangle rect.
+func outline(img *image.RGBA, rect image.Rectangle, c color.Color) {
+	for x := rect.Min.X; x < rect.Max.X; x++ {
+		img.Set(x, rect.Min.Y, c)
+		img.Set(x, rect.Max.Y-1, c)
+	}
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		img.Set(rect.Min.X, y, c)
+		img.Set(rect.Max.X-1, y, c)
+	}
+}
+
+// shape reports whether point x,y is inside a shape. Coordinates are relative to the
+// center of a field and scaled to the range -1 to 1.
+type shape func(x, y float64) bool
+
+// paint fills the pixels of the field with top left corner x0,y0 inside shape s.
+func (r *renderer) paint(img *image.RGBA, x0, y0 int, s shape, c color.Color) {
+	half := float64(r.cell) / 2
+	for y := 0; y < r.cell; y++ {
+		for x := 0; x < r.cell; x++ {
+			if s((float64(x)+0.5-half)/half, (float64(y)+0.5-half)/half) {
+				img.Set(x0+x, y0+y, c)
+			}
+		}
+	}
+}
+
+func circle(cx, cy, rad float64) shape {
+	return func(x, y float64) bool { return (x-cx)*(x-cx)+(y-cy)*(y-cy) <= rad*rad }
+}
+
+func ellipse(rx, ry float64) shape {
+	return func(x, y float64) bool { return x*x/(rx*rx)+y*y/(ry*ry) <= 1 }
+}
+
+// polygon returns the shape of the polygon with vertices xy (x and y alternating).
+func polygon(xy ...float64) shape {
+	return func(x, y float64) bool {
+		in := false
+		n := len(xy) / 2
+		for i, j := 0, n-1; i < n; j, i = i, i+1 {
+			xi, yi, xj, yj := xy[2*i], xy[2*i+1], xy[2*j], xy[2*j+1]
+			if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+				in = !in
+			}
+		}
+		return in
+	}
+}
+
+// star returns the polygon of a star with n points.
+func star(n int, outer, inner float64) shape {
+	xy := make([]float64, 0, 4*n)
+	for i := 0; i < 2*n; i++ {
+		rad := outer
+		if i%2 == 1 {
+			rad = inner
+		}
+		a := math.Pi * (float64(i)/float64(n) - 0.5)
+		xy = append(xy, rad*math.Cos(a), rad*math.Sin(a))
+	}
+	return polygon(xy...)
+}
+
+var glyphs = map[board.Symbol]shape{
+	board.Pyramid: polygon(0, -0.72, 0.72, 0.56, -0.72, 0.56),
+	board.Star:    star(5, 0.72, 0.3),
+	board.Moon: func(x, y float64) bool {
+		return circle(0, 0, 0.68)(x, y) && !circle(0.32, -0.12, 0.52)(x, y)
+	},
+	board.Saturn: func(x, y float64) bool {
+		return circle(0, 0, 0.36)(x, y) || ellipse(0.8, 0.24)(x, y) && !ellipse(0.64, 0.14)(x, y)
+	},
+	board.Cosmic: star(4, 0.76, 0.18),
+}
+
+// glyph draws the glyph of symbol s on the field with top left corner x,y.
+func (r *renderer) glyph(img *image.RGBA, x, y int, s board.Symbol, c color.Color) {
+	if g, ok := glyphs[s]; ok {
+		r.paint(img, x, y, g, c)
+	}
+}
+
+// robot draws a robot on the field with top left corner x,y.
+func (r *renderer) robot(img *image.RGBA, x, y int, c board.Color) {
+	r.paint(img, x, y, circle(0, 0, 0.78), r.style.Wall)
+	r.paint(img, x, y, circle(0, 0, 0.66), r.style.color(c))
+}
@@ -0,0 +1,159 @@
+package raster
+
+import (
+	"bytes"
+	"flag"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/internal/testutil"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+var (
+	testSolution = []board.Move{
+		{Robot: board.Red, Direction: board.East},
+		{Robot: board.Yellow, Direction: board.North},
+		{Robot: board.Yellow, Direction: board.East},
+	}
+)
+
+// golden writes data to the golden file name if the update flag is set and returns
+// the content of the golden file.
+func golden(t *testing.T, name string, data []byte) []byte {
+	t.Helper()
+	name = filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(name, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return want
+}
+
+// equalImages reports whether the images have the same size and pixel colors.
+func equalImages(a, b image.Image) bool {
+	if a.Bounds() != b.Bounds() {
+		return false
+	}
+	r := a.Bounds()
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if color.RGBAModel.Convert(a.At(x, y)) != color.RGBAModel.Convert(b.At(x, y)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func testStyle() *Style {
+	style := DefaultStyle()
+	style.CellSize = 16
+	return style
+}
+
+func TestPNG(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	var buf bytes.Buffer
+	if err := RenderPNG(&buf, b, &Options{Style: testStyle(), Robots: &testutil.Robots, Symbol: board.Star, Color: board.Red}); err != nil {
+		t.Fatal(err)
+	}
+	want := golden(t, "position.png", buf.Bytes())
+	got, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantImg, err := png.Decode(bytes.NewReader(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalImages(got, wantImg) {
+		t.Fatal("image differs from testdata/position.png")
+	}
+
+	if _, err := Render(b, &Options{Style: &Style{}}); err == nil {
+		t.Fatal("invalid cell size: error expected")
+	}
+	// missing colors are taken from the default style
+	img, err := Render(b, &Options{Style: &Style{CellSize: 16}, Robots: &testutil.Robots, Symbol: board.Star, Color: board.Red})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalImages(img, wantImg) {
+		t.Fatal("image of style without colors differs from default style")
+	}
+}
+
+func TestGIF(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	anim := &Animation{Frames: 4, FrameDelay: 5, MoveDelay: 40, FinalDelay: 100}
+	var buf bytes.Buffer
+	if err := RenderGIF(&buf, b, testSolution, &Options{Style: testStyle(), Robots: &testutil.Robots, Symbol: board.Star, Color: board.Red}, anim); err != nil {
+		t.Fatal(err)
+	}
+	want := golden(t, "solution.gif", buf.Bytes())
+	got, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantGIF, err := gif.DecodeAll(bytes.NewReader(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := 1 + len(testSolution)*anim.Frames; len(got.Image) != n {
+		t.Fatalf("got %d frames - expected %d", len(got.Image), n)
+	}
+	if got.Delay[0] != anim.FinalDelay || got.Delay[1] != anim.FrameDelay || got.Delay[anim.Frames] != anim.MoveDelay || got.Delay[len(got.Delay)-1] != anim.FinalDelay {
+		t.Fatalf("invalid delays %v", got.Delay)
+	}
+	if len(got.Image) != len(wantGIF.Image) {
+		t.Fatalf("got %d frames - expected %d", len(got.Image), len(wantGIF.Image))
+	}
+	for i, img := range got.Image {
+		if !equalImages(img, wantGIF.Image[i]) || got.Delay[i] != wantGIF.Delay[i] {
+			t.Fatalf("frame %d differs from testdata/solution.gif", i)
+		}
+	}
+
+	// the last frame shows the final position
+	robots := testutil.Robots
+	for _, m := range testSolution {
+		robots = b.Move(robots, m)
+	}
+	final, err := Render(b, &Options{Style: testStyle(), Robots: &robots, Symbol: board.Star, Color: board.Red})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalImages(got.Image[len(got.Image)-1], final) {
+		t.Fatal("last frame differs from final position")
+	}
+
+	for name, test := range map[string]struct {
+		solution []board.Move
+		opts     *Options
+		anim     *Animation
+	}{
+		"no robots":         {testSolution, nil, nil},
+		"invalid robot":     {[]board.Move{{Robot: board.Silver}}, &Options{Robots: &testutil.Robots}, nil},
+		"invalid direction": {[]board.Move{{Robot: board.Red, Direction: board.NumDirection}}, &Options{Robots: &testutil.Robots}, nil},
+		"no frames":         {testSolution, &Options{Robots: &testutil.Robots}, &Animation{}},
+	} {
+		if err := RenderGIF(io.Discard, b, test.solution, test.opts, test.anim); err == nil {
+			t.Fatalf("%s: error expected", name)
+		}
+	}
+}
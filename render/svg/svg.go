@@ -0,0 +1,301 @@
+// Package svg renders boards as SVG documents. Fields are drawn with the top row
+// y = 15 at the top, the robots as circles and the targets as glyphs in the target
+// color. A solution is drawn as a numbered arrow per robot move.
+package svg
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/coord"
+)
+
+const numField = 16
+
+// Style defines the appearance of a rendered board. Empty colors and colors and
+// glyphs missing in the maps are taken from the default style.
+type Style struct {
+	CellSize   int                    // width and height of a field in pixels
+	Colors     map[board.Color]string // colors of robots and targets, color 0: cosmic target
+	Background string                 // field color
+	Grid       string                 // color of the lines between fields
+	Wall       string                 // wall color
+	Center     string                 // color of the center block
+	Highlight  string                 // color of the highlighted target frame
+	// Glyphs are the SVG path data of the target symbols. The path coordinates
+	// range from -50 to 50, 0,0 being the center of the field.
+	Glyphs map[board.Symbol]string
+}
+
+// DefaultStyle returns the default style.
+func DefaultStyle() *Style {
+	return &Style{
+		CellSize: 40,
+		Colors: map[board.Color]string{
+			board.Yellow: "#f2c200",
+			board.Red:    "#d62828",
+			board.Green:  "#2a9d3f",
+			board.Blue:   "#1f5fbf",
+			board.Silver: "#a0a0a0",
+			0:            "#7b3fa0", // cosmic target
+		},
+		Background: "#f4f1e8",
+		Grid:       "#d8d2c0",
+		Wall:       "#333333",
+		Center:     "#555555",
+		Highlight:  "#000000",
+		Glyphs: map[board.Symbol]string{
+			board.Pyramid: pyramidGlyph,
+			board.Star:    starGlyph(),
+			board.Moon:    moonGlyph,
+			board.Saturn:  saturnGlyph,
+			board.Cosmic:  cosmicGlyph,
+		},
+	}
+}
+
+// withDefaults returns a copy of the style with empty colors and missing colors and
+// glyphs replaced by the ones of the default style.
+func (s *Style) withDefaults() *Style {
+	d, c := DefaultStyle(), *s
+	for _, p := range []struct{ col, def *string }{
+		{&c.Background, &d.Background},
+		{&c.Grid, &d.Grid},
+		{&c.Wall, &d.Wall},
+		{&c.Center, &d.Center},
+		{&c.Highlight, &d.Highlight},
+	} {
+		if *p.col == "" {
+			*p.col = *p.def
+		}
+	}
+	for color, col := range s.Colors {
+		if col != "" {
+			d.Colors[color] = col
+		}
+	}
+	for symbol, glyph := range s.Glyphs {
+		if glyph != "" {
+			d.Glyphs[symbol] = glyph
+		}
+	}
+	c.Colors, c.Glyphs = d.Colors, d.Glyphs
+	return &c
+}
+
+// glyphSize is the width and height of the glyph coordinate space.
+const glyphSize = 100
+
+const (
+	pyramidGlyph = "M 0 -36 L 36 28 L -36 28 Z"
+	moonGlyph    = "M 10 -34 A 34 34 0 1 0 10 34 A 26 26 0 1 1 10 -34 Z"
+	// planet and ring, the inner ring ellipse runs counterclockwise to leave a gap
+	saturnGlyph = "M -18 0 A 18 18 0 1 1 18 0 A 18 18 0 1 1 -18 0 Z " +
+		"M -40 0 A 40 12 0 1 1 40 0 A 40 12 0 1 1 -40 0 Z " +
+		"M -32 0 A 32 7 0 1 0 32 0 A 32 7 0 1 0 -32 0 Z"
+	cosmicGlyph = "M 0 -38 Q 6 -6 38 0 Q 6 6 0 38 Q -6 6 -38 0 Q -6 -6 0 -38 Z"
+)
+
+// starGlyph returns the path data of a five-pointed star.
+func starGlyph() string {
+	var sb strings.Builder
+	for i := 0; i < 10; i++ {
+		r := 36.0
+		if i%2 == 1 {
+			r = 15
+		}
+		a := math.Pi * (float64(i)/5 - 0.5)
+		cmd := "L"
+		if i == 0 {
+			cmd = "M"
+		}
+		fmt.Fprintf(&sb, "%s %.1f %.1f ", cmd, r*math.Cos(a), r*math.Sin(a))
+	}
+	sb.WriteString("Z")
+	return sb.String()
+}
+
+// Options are the rendering options.
+type Options struct {
+	Style    *Style        // nil: default style
+	Robots   *board.Robots // robot positions, nil: no robots
+	Symbol   board.Symbol  // highlighted target symbol, NoSymbol: no target
+	Color    board.Color   // highlighted target color
+	Solution []board.Move  // moves drawn as arrows starting at the robot positions
+}
+
+type renderer struct {
+	w     *bufio.Writer
+	b     *board.Board
+	style *Style
+	cell  float64
+}
+
+// field returns the coordinates of the top left corner of field x,y.
+func (r *renderer) field(x, y int) (float64, float64) {
+	return float64(x) * r.cell, float64(numField-1-y) * r.cell
+}
+
+// center returns the coordinates of the center of field c.
+func (r *renderer) center(c byte) (float64, float64) {
+	x, y := r.field(coord.Btoc(c))
+	return x + r.cell/2, y + r.cell/2
+}
+
+func (r *renderer) printf(format string, args ...any) { fmt.Fprintf(r.w, format, args...) }
+
+func attr(s string) string { return html.EscapeString(s) }
+
+// Render writes the SVG document of board b to w.
+func Render(w io.Writer, b *board.Board, opts *Options) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+	style := DefaultStyle()
+	if opts.Style != nil {
+		style = opts.Style.withDefaults()
+	}
+	if style.CellSize <= 0 {
+		return fmt.Errorf("svg: invalid cell size %d", style.CellSize)
+	}
+	var path []arrow
+	if opts.Solution != nil {
+		if opts.Robots == nil {
+			return fmt.Errorf("svg: solution without robot positions")
+		}
+		var err error
+		if path, err = arrows(b, *opts.Robots, opts.Solution); err != nil {
+			return err
+		}
+	}
+
+	r := &renderer{w: bufio.NewWriter(w), b: b, style: style, cell: float64(style.CellSize)}
+	size := numField * style.CellSize
+	r.printf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", size, size, size, size)
+	r.markers()
+	r.printf("<rect width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", size, size, attr(style.Background))
+	r.grid()
+	r.printf("<rect x=\"%g\" y=\"%g\" width=\"%g\" height=\"%g\" fill=\"%s\"/>\n", 7*r.cell, 7*r.cell, 2*r.cell, 2*r.cell, attr(style.Center))
+	for c, f := range b.Fields {
+		if f.Symbol != board.NoSymbol {
+			r.target(byte(c), f, f.Symbol == opts.Symbol && f.Color == opts.Color)
+		}
+	}
+	r.walls()
+	if opts.Robots != nil {
+		for i, c := range opts.Robots {
+			r.robot(c, board.RobotColors[i])
+		}
+	}
+	for i, a := range path {
+		r.arrow(i+1, a)
+	}
+	r.printf("</svg>\n")
+	return r.w.Flush()
+}
+
+func (r *renderer) grid() {
+	r.printf("<g stroke=\"%s\" stroke-width=\"1\">\n", attr(r.style.Grid))
+	for i := 1; i < numField; i++ {
+		p := float64(i) * r.cell
+		r.printf("<line x1=\"%g\" y1=\"0\" x2=\"%g\" y2=\"%g\"/>\n", p, p, numField*r.cell)
+		r.printf("<line x1=\"0\" y1=\"%g\" x2=\"%g\" y2=\"%g\"/>\n", p, numField*r.cell, p)
+	}
+	r.printf("</g>\n")
+}
+
+func (r *renderer) walls() {
+	width := math.Max(2, r.cell/10)
+	r.printf("<g stroke=\"%s\" stroke-width=\"%g\" stroke-linecap=\"square\">\n", attr(r.style.Wall), width)
+	line := func(x1, y1, x2, y2 float64) {
+		r.printf("<line x1=\"%g\" y1=\"%g\" x2=\"%g\" y2=\"%g\"/>\n", x1, y1, x2, y2)
+	}
+	for x := 0; x < numField; x++ {
+		for y := 0; y < numField; y++ {
+			w := r.b.Field(x, y).Walls
+			x0, y0 := r.field(x, y)
+			x1, y1 := x0+r.cell, y0+r.cell
+			if w&board.NorthWall != 0 {
+				line(x0, y0, x1, y0)
+			}
+			if w&board.WestWall != 0 {
+				line(x0, y0, x0, y1)
+			}
+			// east and south walls only at the border, inner ones are drawn by the neighbor
+			if w&board.EastWall != 0 && x == numField-1 {
+				line(x1, y0, x1, y1)
+			}
+			if w&board.SouthWall != 0 && y == 0 {
+				line(x0, y1, x1, y1)
+			}
+		}
+	}
+	r.printf("</g>\n")
+}
+
+// target draws the glyph of the target on field f.
+func (r *renderer) target(c byte, f *board.Field, highlight bool) {
+	cx, cy := r.center(c)
+	if highlight {
+		x, y := cx-r.cell/2+2, cy-r.cell/2+2
+		r.printf("<rect x=\"%g\" y=\"%g\" width=\"%g\" height=\"%g\" fill=\"none\" stroke=\"%s\" stroke-width=\"2\"/>\n", x, y, r.cell-4, r.cell-4, attr(r.style.Highlight))
+	}
+	r.printf("<path d=\"%s\" fill=\"%s\" transform=\"translate(%g %g) scale(%g)\"/>\n",
+		attr(r.style.Glyphs[f.Symbol]), attr(r.style.Colors[f.Color]), cx, cy, r.cell/glyphSize)
+}
+
+func (r *renderer) robot(c byte, color board.Color) {
+	cx, cy := r.center(c)
+	r.printf("<circle cx=\"%g\" cy=\"%g\" r=\"%g\" fill=\"%s\" stroke=\"%s\" stroke-width=\"2\"/>\n",
+		cx, cy, r.cell*0.38, attr(r.style.Colors[color]), attr(r.style.Wall))
+}
+
+// markers defines the arrow heads of the solution path, one per robot color.
+func (r *renderer) markers() {
+	r.printf("<defs>\n")
+	for _, color := range board.RobotColors {
+		r.printf("<marker id=\"arrow-%s\" viewBox=\"0 0 10 10\" refX=\"8\" refY=\"5\" markerWidth=\"4\" markerHeight=\"4\" orient=\"auto\">", color)
+		r.printf("<path d=\"M 0 0 L 10 5 L 0 10 Z\" fill=\"%s\"/></marker>\n", attr(r.style.Colors[color]))
+	}
+	r.printf("</defs>\n")
+}
+
+// arrow is a robot move of the solution path.
+type arrow struct {
+	robot    board.Color
+	from, to byte
+}
+
+// arrows returns the moves of solution executed from robot positions robots.
+func arrows(b *board.Board, robots board.Robots, solution []board.Move) ([]arrow, error) {
+	path := make([]arrow, 0, len(solution))
+	for _, m := range solution {
+		i := board.RobotIndex(m.Robot)
+		if i == -1 || m.Direction >= board.NumDirection {
+			return nil, fmt.Errorf("svg: invalid move %s", m)
+		}
+		from := robots[i]
+		robots = b.Move(robots, m)
+		path = append(path, arrow{robot: m.Robot, from: from, to: robots[i]})
+	}
+	return path, nil
+}
+
+// arrow draws move n of the solution path.
+func (r *renderer) arrow(n int, a arrow) {
+	if a.from == a.to {
+		return
+	}
+	x1, y1 := r.center(a.from)
+	x2, y2 := r.center(a.to)
+	color := attr(r.style.Colors[a.robot])
+	r.printf("<line x1=\"%g\" y1=\"%g\" x2=\"%g\" y2=\"%g\" stroke=\"%s\" stroke-width=\"%g\" stroke-opacity=\"0.8\" marker-end=\"url(#arrow-%s)\"/>\n",
+		x1, y1, x2, y2, color, r.cell/8, a.robot)
+	r.printf("<text x=\"%g\" y=\"%g\" font-family=\"sans-serif\" font-size=\"%g\" text-anchor=\"middle\" dominant-baseline=\"central\" fill=\"%s\">%d</text>\n",
+		(x1+x2)/2, (y1+y2)/2, r.cell/3, attr(r.style.Wall), n)
+}
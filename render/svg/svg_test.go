@@ -0,0 +1,100 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/internal/testutil"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+var (
+	testSolution = []board.Move{
+		{Robot: board.Red, Direction: board.East},
+		{Robot: board.Red, Direction: board.South},
+		{Robot: board.Yellow, Direction: board.North},
+	}
+)
+
+// checkXML checks that data is a well-formed XML document.
+func checkXML(t *testing.T, data []byte) {
+	t.Helper()
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	style := DefaultStyle()
+	style.CellSize = 24
+	style.Colors[board.Red] = "crimson"
+
+	tests := []struct {
+		name string
+		opts *Options
+	}{
+		{"board", nil},
+		{"position", &Options{Robots: &testutil.Robots, Symbol: board.Star, Color: board.Red}},
+		{"solution", &Options{Style: style, Robots: &testutil.Robots, Symbol: board.Star, Color: board.Red, Solution: testSolution}},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := Render(&buf, b, test.opts); err != nil {
+			t.Fatal(err)
+		}
+		checkXML(t, buf.Bytes())
+		golden := filepath.Join("testdata", test.name+".svg")
+		if *update {
+			if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Fatalf("%s: output differs from %s", test.name, golden)
+		}
+	}
+
+	for name, opts := range map[string]*Options{
+		"solution without robots": {Solution: testSolution},
+		"invalid robot":           {Robots: &testutil.Robots, Solution: []board.Move{{Robot: board.Silver}}},
+		"invalid direction":       {Robots: &testutil.Robots, Solution: []board.Move{{Robot: board.Red, Direction: board.NumDirection}}},
+		"invalid cell size":       {Style: &Style{}},
+	} {
+		if err := Render(io.Discard, b, opts); err == nil {
+			t.Fatalf("%s: error expected", name)
+		}
+	}
+
+	// partial style: missing colors and glyphs are taken from the default style
+	var partial, def bytes.Buffer
+	opts := &Options{Style: &Style{CellSize: 40}, Robots: &testutil.Robots, Symbol: board.Star, Color: board.Red, Solution: testSolution}
+	if err := Render(&partial, b, opts); err != nil {
+		t.Fatal(err)
+	}
+	opts.Style = nil
+	if err := Render(&def, b, opts); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(partial.Bytes(), def.Bytes()) {
+		t.Fatal("partial style: output differs from default style")
+	}
+}
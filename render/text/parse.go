@@ -0,0 +1,247 @@
+package text
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/go-ricrob/game/board"
+)
+
+const (
+	lineWidth = numField*(cellWidth+1) + 1
+	numLine   = 2*numField + 1
+)
+
+// SyntaxError is the error returned for malformed board text.
+type SyntaxError struct {
+	Line, Column int // 1-based position of the error
+	Msg          string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("text: line %d column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+type parser struct {
+	first  int // line number of the first board line
+	lines  []string
+	fields [board.NumField]board.Field
+	robots board.Robots
+	found  [board.NumRobot]bool
+	symbol board.Symbol // highlighted target, NoSymbol: none
+	color  board.Color
+	moves  map[byte]int // min moves shown
+}
+
+func (p *parser) errorf(line, col int, format string, args ...any) error {
+	return &SyntaxError{Line: p.first + line, Column: col + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Parse reads the text representation of a board written by Render and returns
+// the board and the rendering options, so that rendering the board with these
+// options reproduces the text. Blank lines before and after the board and blanks at
+// the end of lines are ignored. Robot positions are returned if all robots are
+// shown, the target if a target is highlighted. If min moves are shown they need
+// to match the board.
+func Parse(r io.Reader) (*board.Board, *Options, error) {
+	p := &parser{first: 1, moves: map[byte]int{}}
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), " \t\r")
+		if line == "" && len(p.lines) == 0 {
+			p.first++
+			continue
+		}
+		p.lines = append(p.lines, line)
+	}
+	if err := s.Err(); err != nil {
+		return nil, nil, err
+	}
+	for len(p.lines) != 0 && p.lines[len(p.lines)-1] == "" {
+		p.lines = p.lines[:len(p.lines)-1]
+	}
+	if len(p.lines) != numLine {
+		return nil, nil, p.errorf(len(p.lines), 0, "%d lines - expected %d", len(p.lines), numLine)
+	}
+
+	for i, line := range p.lines {
+		if len(line) > lineWidth {
+			return nil, nil, p.errorf(i, lineWidth, "line too long")
+		}
+		line += strings.Repeat(" ", lineWidth-len(line))
+		var err error
+		if i%2 == 0 {
+			err = p.parseWalls(i, line)
+		} else {
+			err = p.parseCells(i, line)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return p.result()
+}
+
+// parseWalls parses the horizontal wall line i.
+func (p *parser) parseWalls(i int, line string) error {
+	y := numField - 1 - i/2 // row south of the line, -1: south border
+	for x := 0; x < numField; x++ {
+		col := x * (cellWidth + 1)
+		if line[col] != '+' {
+			return p.errorf(i, col, "'+' expected")
+		}
+		switch line[col+1 : col+1+cellWidth] {
+		case "----":
+			if y >= 0 {
+				p.fields[fieldCoord(x, y)].Walls |= board.NorthWall
+			}
+			if y < numField-1 {
+				p.fields[fieldCoord(x, y+1)].Walls |= board.SouthWall
+			}
+		case "    ":
+		default:
+			return p.errorf(i, col+1, "wall \"----\" or blanks expected")
+		}
+	}
+	if line[lineWidth-1] != '+' {
+		return p.errorf(i, lineWidth-1, "'+' expected")
+	}
+	return nil
+}
+
+// parseCells parses the cell line i.
+func (p *parser) parseCells(i int, line string) error {
+	y := numField - 1 - i/2
+	for x := 0; x <= numField; x++ {
+		col := x * (cellWidth + 1)
+		switch line[col] {
+		case '|':
+			if x > 0 {
+				p.fields[fieldCoord(x-1, y)].Walls |= board.EastWall
+			}
+			if x < numField {
+				p.fields[fieldCoord(x, y)].Walls |= board.WestWall
+			}
+		case ' ':
+		default:
+			return p.errorf(i, col, "wall '|' or blank expected")
+		}
+		if x < numField {
+			if err := p.parseCell(i, col+1, x, y, line[col+1:col+1+cellWidth]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseCell parses the content of the cell of field x,y starting at column col of line i.
+func (p *parser) parseCell(i, col, x, y int, s string) error {
+	c := fieldCoord(x, y)
+	if isCenter(x, y) != (s == center) {
+		if s == center {
+			return p.errorf(i, col, "center block outside of center")
+		}
+		return p.errorf(i, col, "center block %q expected", center)
+	}
+	if s == center {
+		return nil
+	}
+
+	if s[0] != ' ' {
+		r := strings.IndexByte(string(board.RobotLetters[:]), s[0])
+		if r == -1 {
+			return p.errorf(i, col, "invalid robot %q", s[0])
+		}
+		if p.found[r] {
+			return p.errorf(i, col, "duplicate robot %q", s[0])
+		}
+		p.robots[r], p.found[r] = c, true
+	}
+
+	f := &p.fields[c]
+	switch {
+	case s[1:3] == "  ":
+	case s[1] >= '0' && s[1] <= '9' || s[1] == ' ' && s[2] >= '0' && s[2] <= '9':
+		n, err := strconv.Atoi(strings.TrimLeft(s[1:3], " "))
+		if err != nil {
+			return p.errorf(i, col+1, "invalid min moves %q", s[1:3])
+		}
+		p.moves[c] = n
+	default:
+		symbol, ok := board.SymbolByLetter(s[1])
+		if !ok {
+			return p.errorf(i, col+1, "invalid symbol %q", s[1])
+		}
+		f.Symbol = symbol
+		color, ok := board.ColorByLetter(s[2])
+		if !ok && s[2] != ' ' || (f.Symbol == board.Cosmic) != (color == 0) {
+			return p.errorf(i, col+2, "invalid color %q of symbol %q", s[2], s[1])
+		}
+		f.Color = color
+	}
+
+	switch s[3] {
+	case ' ':
+	case '*':
+		if f.Symbol == board.NoSymbol {
+			return p.errorf(i, col+3, "highlighted field without target")
+		}
+		if p.symbol != board.NoSymbol {
+			return p.errorf(i, col+3, "more than one highlighted target")
+		}
+		p.symbol, p.color = f.Symbol, f.Color
+	default:
+		return p.errorf(i, col+3, "highlight '*' or blank expected")
+	}
+	return nil
+}
+
+// result creates board and options.
+func (p *parser) result() (*board.Board, *Options, error) {
+	b, err := board.NewFromFields(&p.fields)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := &Options{Symbol: p.symbol, Color: p.color}
+	switch n := countTrue(p.found[:]); n {
+	case 0:
+	case board.NumRobot:
+		opts.Robots = &p.robots
+	default:
+		return nil, nil, fmt.Errorf("text: %d robots - expected none or %d", n, board.NumRobot)
+	}
+	if len(p.moves) != 0 {
+		if p.symbol == board.NoSymbol {
+			return nil, nil, errors.New("text: min moves without highlighted target")
+		}
+		opts.MinMoves = true
+		minMoves := b.TargetMinMoves(p.symbol, p.color)
+		for c, f := range b.Fields {
+			x, y := c>>4, c&0x0f
+			if f.Symbol != board.NoSymbol || isCenter(x, y) {
+				continue
+			}
+			if n, ok := p.moves[byte(c)]; ok != (minMoves[c] != -1) || ok && n != minMoves[c] {
+				return nil, nil, p.errorf(2*(numField-1-y)+1, x*(cellWidth+1)+2, "min moves %d - expected %d", n, minMoves[c])
+			}
+		}
+	}
+	return b, opts, nil
+}
+
+func fieldCoord(x, y int) byte { return byte(x)<<4 | byte(y) }
+
+func countTrue(bs []bool) int {
+	n := 0
+	for _, b := range bs {
+		if b {
+			n++
+		}
+	}
+	return n
+}
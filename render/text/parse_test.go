@@ -0,0 +1,108 @@
+package text
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/internal/testutil"
+)
+
+func TestParse(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	for _, name := range []string{"board", "position", "minmoves"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name+".txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b2, opts, err := Parse(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		for c := range b.Fields {
+			if *b2.Fields[c] != *b.Fields[c] {
+				t.Fatalf("%s: field %d,%d: %s - expected %s", name, c>>4, c&0x0f, b2.Fields[c], b.Fields[c])
+			}
+		}
+		if name != "board" && (opts.Robots == nil || *opts.Robots != testutil.Robots || opts.Symbol != board.Star || opts.Color != board.Red) {
+			t.Fatalf("%s: invalid options %+v", name, opts)
+		}
+		if opts.MinMoves != (name == "minmoves") {
+			t.Fatalf("%s: min moves %t", name, opts.MinMoves)
+		}
+		var buf bytes.Buffer
+		if err := Render(&buf, b2, opts); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Fatalf("%s: rendering parsed board differs:\n%s", name, buf.Bytes())
+		}
+	}
+
+	// edited board with silver target
+	if err := b.AddWalls(2, 10, board.NorthWall); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.SetTarget(5, 5, board.Star, board.Silver); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Render(&buf, b, nil); err != nil {
+		t.Fatal(err)
+	}
+	b2, _, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for c := range b.Fields {
+		if *b2.Fields[c] != *b.Fields[c] {
+			t.Fatalf("edited board: field %d,%d: %s - expected %s", c>>4, c&0x0f, b2.Fields[c], b.Fields[c])
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "minmoves.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(data), "\n")
+	// replace returns the text with the characters at line and column (1-based) replaced by s.
+	replace := func(line, col int, s string) string {
+		l := append([]string(nil), lines...)
+		l[line-1] = l[line-1][:col-1] + s + l[line-1][col-1+len(s):]
+		return strings.Join(l, "\n")
+	}
+	tests := []struct {
+		text      string
+		line, col int
+	}{
+		{replace(1, 1, "-"), 1, 1},
+		{replace(3, 7, "-- -"), 3, 7},
+		{replace(2, 6, "x"), 2, 6},
+		{replace(2, 2, "X"), 2, 2},
+		{replace(10, 7, "R"), 10, 7},  // duplicate red robot
+		{replace(4, 33, "Xb"), 4, 33}, // invalid symbol
+		{replace(4, 33, "Mx"), 4, 34}, // invalid color
+		{replace(4, 35, "*"), 12, 15}, // second highlighted target
+		{replace(2, 5, "*"), 2, 5},    // highlighted field without target
+		{replace(16, 37, "  "), 16, 37},
+		{replace(2, 3, " 7"), 2, 3}, // wrong min moves
+		{strings.Join(lines[:10], "\n"), 11, 1},
+		{"\n\n" + replace(2, 6, "x"), 4, 6},
+	}
+	for _, test := range tests {
+		_, _, err := Parse(strings.NewReader(test.text))
+		var serr *SyntaxError
+		if !errors.As(err, &serr) {
+			t.Fatalf("line %d column %d: error %v - expected syntax error", test.line, test.col, err)
+		}
+		if serr.Line != test.line || serr.Column != test.col {
+			t.Fatalf("error %s - expected line %d column %d", serr, test.line, test.col)
+		}
+	}
+}
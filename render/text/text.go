@@ -0,0 +1,136 @@
+// Package text provides a plain text representation of boards. Render writes it,
+// Parse reads it, so boards can be written down as pictures in tests and reports.
+//
+// A board is drawn as a grid of 16x16 cells, the top row being y = 15 and the left
+// column x = 0. Horizontal lines show the walls between rows, '+' marks the
+// corners and "----" a wall along a cell. Between the cells of a row '|' marks a
+// wall. Each cell is four characters wide:
+//
+//	character 1: robot   Y yellow, R red, G green, B blue
+//	character 2: symbol  P pyramid, S star, M moon, T saturn, C cosmic
+//	character 3: color   y yellow, r red, g green, b blue, s silver, blank for no color
+//	character 4: '*' marks the highlighted target
+//
+// The cells of the center block are filled with "####". If min moves are shown,
+// characters 2 and 3 of cells without a target hold the minimal number of moves
+// to the highlighted target, right aligned.
+//
+// Example: columns 0 to 3 of rows 11 and 10 of a board with the yellow robot on
+// field 0,10 and the highlighted red star target on field 2,10, which has a south
+// and an east wall (trailing blanks omitted).
+//
+//	|
+//	+    +    +    +    +
+//	|Y          Sr*|
+//	+    +    +----+    +
+package text
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-ricrob/game/board"
+)
+
+const (
+	numField  = 16
+	cellWidth = 4
+	center    = "####"
+)
+
+// Options are the rendering options.
+type Options struct {
+	Robots   *board.Robots // robot positions, nil: no robots
+	Symbol   board.Symbol  // highlighted target symbol, NoSymbol: no target
+	Color    board.Color   // highlighted target color
+	MinMoves bool          // show the minimal number of moves to the highlighted target
+}
+
+func isCenter(x, y int) bool { return x >= 7 && x <= 8 && y >= 7 && y <= 8 }
+
+// Render writes the text representation of board b to w.
+func Render(w io.Writer, b *board.Board, opts *Options) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+	var minMoves *[board.NumField]int
+	if opts.MinMoves {
+		if opts.Symbol == board.NoSymbol {
+			return fmt.Errorf("text: min moves without target")
+		}
+		if !b.HasTarget(opts.Symbol, opts.Color) {
+			return fmt.Errorf("text: min moves to target %s %s not on board", opts.Symbol, opts.Color)
+		}
+		minMoves = b.TargetMinMoves(opts.Symbol, opts.Color)
+	}
+
+	bw := bufio.NewWriter(w)
+	var line strings.Builder
+	hline := func(y int, w board.Wall) { // walls w of row y
+		line.Reset()
+		for x := 0; x < numField; x++ {
+			line.WriteByte('+')
+			if b.Field(x, y).Walls&w != 0 {
+				line.WriteString("----")
+			} else {
+				line.WriteString("    ")
+			}
+		}
+		line.WriteString("+\n")
+		bw.WriteString(line.String())
+	}
+
+	for y := numField - 1; y >= 0; y-- {
+		hline(y, board.NorthWall)
+		line.Reset()
+		for x := 0; x < numField; x++ {
+			f := b.Field(x, y)
+			if f.Walls&board.WestWall != 0 {
+				line.WriteByte('|')
+			} else {
+				line.WriteByte(' ')
+			}
+			line.WriteString(cell(b, x, y, opts, minMoves))
+		}
+		if b.Field(numField-1, y).Walls&board.EastWall != 0 {
+			line.WriteString("|\n")
+		} else {
+			line.WriteString(" \n")
+		}
+		bw.WriteString(line.String())
+	}
+	hline(0, board.SouthWall)
+	return bw.Flush()
+}
+
+// cell returns the content of the cell of field x,y.
+func cell(b *board.Board, x, y int, opts *Options, minMoves *[board.NumField]int) string {
+	if isCenter(x, y) {
+		return center
+	}
+	c := [cellWidth]byte{' ', ' ', ' ', ' '}
+	coord := byte(x)<<4 | byte(y)
+	if opts.Robots != nil {
+		for i, rc := range opts.Robots {
+			if rc == coord {
+				c[0] = board.RobotLetters[i]
+			}
+		}
+	}
+	f := b.Field(x, y)
+	switch {
+	case f.Symbol != board.NoSymbol:
+		c[1] = f.Symbol.Letter()
+		if l := f.Color.Letter(); l != 0 {
+			c[2] = l
+		}
+		if opts.Symbol == f.Symbol && opts.Color == f.Color {
+			c[3] = '*'
+		}
+	case minMoves != nil && minMoves[coord] != -1:
+		copy(c[1:3], fmt.Sprintf("%2d", minMoves[coord]))
+	}
+	return string(c[:])
+}
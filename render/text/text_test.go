@@ -0,0 +1,52 @@
+package text
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/internal/testutil"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestRender(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	tests := []struct {
+		name string
+		opts *Options
+	}{
+		{"board", nil},
+		{"position", &Options{Robots: &testutil.Robots, Symbol: board.Star, Color: board.Red}},
+		{"minmoves", &Options{Robots: &testutil.Robots, Symbol: board.Star, Color: board.Red, MinMoves: true}},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := Render(&buf, b, test.opts); err != nil {
+			t.Fatal(err)
+		}
+		golden := filepath.Join("testdata", test.name+".txt")
+		if *update {
+			if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Fatalf("%s: got\n%s\nwant\n%s", test.name, buf.Bytes(), want)
+		}
+	}
+
+	if err := Render(&bytes.Buffer{}, b, &Options{MinMoves: true}); err == nil {
+		t.Fatal("min moves without target: error expected")
+	}
+	if err := Render(&bytes.Buffer{}, b, &Options{Symbol: board.Star, Color: board.Silver, MinMoves: true}); err == nil {
+		t.Fatal("min moves to target not on board: error expected")
+	}
+}
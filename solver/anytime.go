@@ -0,0 +1,96 @@
+package solver
+
+import (
+	"context"
+
+	"github.com/go-ricrob/game/board"
+)
+
+// Result is a solution reported by the anytime solver together with the lower bound
+// of the number of moves known when it was reported.
+type Result struct {
+	Solution   Solution
+	LowerBound int
+}
+
+// Optimal reports whether the solution is proven to be optimal.
+func (r Result) Optimal() bool { return len(r.Solution) == r.LowerBound }
+
+// minAnytimeLimit is the minimal number of states expanded by an improving search.
+const minAnytimeLimit = 1 << 10
+
+// anytime reports improving solutions to fn until the optimum is proven.
+//
+// A weighted best first search finds a first solution quickly. Afterwards the
+// iterations of the depth first search raise the lower bound until it reaches the
+// length of the best solution or a solution of the iteration depth is found. Between
+// the iterations a weighted best first search, limited to the number of states
+// expanded by the last iteration, looks for a shorter solution.
+func (s *search) anytime(fn func(Result)) error {
+	lower := s.estimate(&s.start)
+	if lower == -1 {
+		return ErrNoSolution
+	}
+	best, ok, err := s.bounded(s.maxDepth, boundedWeight, 0)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNoSolution
+	}
+	fn(Result{Solution: best, LowerBound: lower})
+
+	for lower < len(best) {
+		expanded := s.expanded
+		s.solutions = s.solutions[:0]
+		s.depth = lower
+		s.progress()
+		found := s.dfs(state{robots: s.start}, lower)
+		if s.err != nil {
+			return s.err
+		}
+		if found {
+			fn(Result{Solution: s.solutions[0], LowerBound: lower})
+			return nil
+		}
+		lower++
+		if lower == len(best) {
+			fn(Result{Solution: best, LowerBound: lower})
+			return nil
+		}
+		limit := max(s.expanded-expanded, minAnytimeLimit)
+		sol, ok, err := s.bounded(len(best)-1, boundedWeight, limit)
+		if err != nil {
+			return err
+		}
+		if ok {
+			best = sol
+			fn(Result{Solution: best, LowerBound: lower})
+		}
+	}
+	return nil
+}
+
+// Anytime solves the puzzle given by robot positions and target and reports solutions
+// of decreasing length to fn (see function Anytime).
+func (s *Session) Anytime(ctx context.Context, robots board.Robots, symbol board.Symbol, color board.Color, fn func(Result), opts ...Option) error {
+	c := newConfig(opts)
+	c.all = false
+	srch, err := s.search(ctx, robots, symbol, color, c)
+	if err != nil {
+		return err
+	}
+	defer s.pool.Put(srch)
+	defer srch.done()
+	return srch.anytime(fn)
+}
+
+// Anytime solves the puzzle given by board, robot positions and target and reports
+// solutions of decreasing length to fn, starting with a solution found quickly, until
+// a solution is proven to be optimal or the context is done. Each result carries the
+// lower bound of the number of moves known at the time. The last result reported is
+// optimal if Anytime returns nil and the best found so far otherwise.
+// Option AllOptimal is ignored.
+func Anytime(ctx context.Context, b *board.Board, robots board.Robots, symbol board.Symbol, color board.Color, fn func(Result), opts ...Option) error {
+	return NewSession(b).Anytime(ctx, robots, symbol, color, fn, opts...)
+}
@@ -0,0 +1,70 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/internal/testutil"
+)
+
+func TestAnytime(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	n := 20
+	if testing.Short() {
+		n = 5
+	}
+	for _, p := range testutil.RandomPuzzles(b, n) {
+		var results []Result
+		if err := Anytime(context.Background(), b, p.Robots, p.Symbol, p.Color, func(r Result) { results = append(results, r) }); err != nil {
+			t.Fatal(err)
+		}
+		if len(results) == 0 {
+			t.Fatalf("%v: no results", p)
+		}
+		for i, r := range results {
+			testutil.CheckSolution(t, b, p, r.Solution)
+			if r.LowerBound > len(r.Solution) {
+				t.Fatalf("%v: lower bound %d exceeds solution length %d", p, r.LowerBound, len(r.Solution))
+			}
+			if i == 0 {
+				continue
+			}
+			if prev := results[i-1]; len(r.Solution) > len(prev.Solution) || r.LowerBound < prev.LowerBound || len(r.Solution) == len(prev.Solution) && r.LowerBound == prev.LowerBound {
+				t.Fatalf("%v: result %d (%d moves, lower bound %d) does not improve result %d (%d moves, lower bound %d)", p, i, len(r.Solution), r.LowerBound, i-1, len(prev.Solution), prev.LowerBound)
+			}
+		}
+		last := results[len(results)-1]
+		if !last.Optimal() {
+			t.Fatalf("%v: last result %s with lower bound %d not optimal", p, last.Solution, last.LowerBound)
+		}
+		if opt := bfs(b, p); len(last.Solution) != opt {
+			t.Fatalf("%v: solution %s with %d moves - expected %d moves", p, last.Solution, len(last.Solution), opt)
+		}
+	}
+}
+
+func TestAnytimeTimeout(t *testing.T) {
+	var deep *corpusPuzzle
+	for _, p := range readCorpus(t) {
+		if deep == nil || p.moves > deep.moves {
+			deep = p
+		}
+	}
+	b := board.New(deep.tiles)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	var results []Result
+	err := Anytime(ctx, b, deep.Robots, deep.Symbol, deep.Color, func(r Result) { results = append(results, r) })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("%s: error %v - expected %v", deep, err, context.DeadlineExceeded)
+	}
+	for _, r := range results {
+		testutil.CheckSolution(t, b, deep.Puzzle, r.Solution)
+		if r.LowerBound > deep.moves || len(r.Solution) < deep.moves {
+			t.Fatalf("%s: solution %d moves lower bound %d - optimum %d", deep, len(r.Solution), r.LowerBound, deep.moves)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-ricrob/game/board"
+)
+
+// backward search table: minimal number of moves of one robot to the target field
+// with the other robots not moving; -1: unreachable
+type distTable [board.NumField]int8
+
+// backward calculates the distance table of robot i by a breadth first search from
+// the target field using reverse moves. The position of robot i in r is ignored.
+func (s *search) backward(r board.Robots, i int) *distTable {
+	t := &distTable{}
+	for c := range t {
+		t[c] = -1
+	}
+	r[i] = s.target
+	for j, c := range r {
+		if j != i && c == s.target { // target field blocked
+			return t
+		}
+	}
+	t[s.target] = 0
+	queue := append(make([]byte, 0, board.NumField), s.target)
+	var buf [board.NumField]byte
+	for len(queue) != 0 {
+		c := queue[0]
+		queue = queue[1:]
+		r[i] = c
+		for _, d := range board.Directions {
+			for _, src := range s.b.Sources(buf[:0], c, d, &r) {
+				if t[src] == -1 {
+					t[src] = t[c] + 1
+					queue = append(queue, src)
+				}
+			}
+		}
+	}
+	return t
+}
+
+// bidirectional finds an optimal solution combining a forward breadth first search
+// over all robots with backward searches from the target field.
+//
+// Each solution ends with a sequence of moves of the robot reaching the target.
+// For every state of the forward search the backward distance tables give the
+// length of the shortest such sequence with the other robots not moving anymore.
+// The forward search therefore only needs to reach the state where the last
+// sequence of target robot moves starts.
+func (s *search) bidirectional() (Solution, error) {
+	tables := map[uint64]*distTable{} // other robot positions and robot -> distance table
+	table := func(r board.Robots, i int) *distTable {
+		r[i] = 0
+		key := (&state{robots: r}).key() | uint64(i)<<40
+		t, ok := tables[key]
+		if !ok {
+			t = s.backward(r, i)
+			tables[key] = t
+		}
+		return t
+	}
+	if s.isGoal(&s.start) {
+		return Solution{}, nil
+	}
+	var robots []int // robots which can reach the target
+	for i := range s.start {
+		if s.allowed[i] && (s.robot == -1 || s.robot == i) {
+			robots = append(robots, i)
+		}
+	}
+
+	const inf = int(^uint(0) >> 1)
+	best, bestNode, bestRobot := inf, -1, -1
+	nodes, visited := s.nodes, s.best
+	nodes = append(nodes, node{state: state{robots: s.start}, parent: -1})
+	visited[nodes[0].key()] = 0
+	defer func() { s.nodes = nodes[:0] }() // keep memory for reuse
+
+	var buf [board.NumRobot * board.NumDirection]child
+	for depth, first, last := 0, 0, 1; first != last; depth, first, last = depth+1, last, len(nodes) {
+		for n := first; n < last; n++ {
+			r := &nodes[n].robots
+			for _, i := range robots {
+				if d := int(table(*r, i)[r[i]]); d != -1 && depth+d < best {
+					best, bestNode, bestRobot = depth+d, n, i
+				}
+			}
+		}
+		// states of the next level need at least one more forward and backward move
+		if best <= depth+2 {
+			break
+		}
+		s.depth = depth
+		s.progress()
+		for n := first; n < last; n++ {
+			// no improvement possible
+			if h := s.estimate(&nodes[n].robots); h == -1 || depth+h >= best {
+				continue
+			}
+			s.expanded++
+			if s.expanded%ctxCheckInterval == 0 {
+				if err := s.ctx.Err(); err != nil {
+					return nil, err
+				}
+				s.progress()
+			}
+			children := s.children(&nodes[n].state, n == 0, buf[:0])
+			if s.observer != nil {
+				s.generated += len(children)
+			}
+			for _, ch := range children {
+				key := ch.key()
+				if _, ok := visited[key]; ok {
+					continue
+				}
+				if h := s.estimate(&ch.robots); h == -1 || depth+1+h >= best {
+					continue
+				}
+				visited[key] = depth + 1
+				nodes = append(nodes, node{state: ch.state, moves: depth + 1, parent: n, move: ch.move})
+			}
+		}
+		if depth+1 >= s.maxDepth {
+			break
+		}
+	}
+	if bestNode == -1 || best > s.maxDepth {
+		return nil, ErrNoSolution
+	}
+
+	// forward part
+	sol := make(Solution, best)
+	for n := bestNode; nodes[n].parent != -1; n = nodes[n].parent {
+		sol[nodes[n].moves-1] = nodes[n].move
+	}
+	// backward part: follow decreasing distances
+	r := nodes[bestNode].robots
+	t := table(r, bestRobot)
+	for k := nodes[bestNode].moves; k < best; k++ {
+		c := r[bestRobot]
+		for _, d := range board.Directions {
+			if n := s.b.Stop(c, d, &r); t[n] == t[c]-1 {
+				sol[k] = board.Move{Robot: board.RobotColors[bestRobot], Direction: d}
+				r[bestRobot] = n
+				break
+			}
+		}
+	}
+	return sol, nil
+}
+
+// ErrUnsupportedOption is returned by SolveBidirectional for options the bidirectional
+// search does not support.
+var ErrUnsupportedOption = errors.New("option not supported by bidirectional search")
+
+// checkBidirectional returns an error if config c holds options not supported by the
+// bidirectional search. The backward searches do not know which robots were moved
+// before and which move was first, so MaxRobots and ForbiddenFirstMoves cannot be
+// applied to the sequence of target robot moves.
+func (c *config) checkBidirectional() error {
+	switch {
+	case c.all:
+		return fmt.Errorf("%w: AllOptimal", ErrUnsupportedOption)
+	case c.maxRobots != 0:
+		return fmt.Errorf("%w: MaxRobots", ErrUnsupportedOption)
+	case len(c.forbidden) != 0:
+		return fmt.Errorf("%w: ForbiddenFirstMoves", ErrUnsupportedOption)
+	}
+	return nil
+}
+
+// SolveBidirectional returns an optimal solution of the puzzle given by robot positions
+// and target using a bidirectional search. The options AllOptimal, MaxRobots and
+// ForbiddenFirstMoves are not supported and result in an ErrUnsupportedOption error.
+func (s *Session) SolveBidirectional(ctx context.Context, robots board.Robots, symbol board.Symbol, color board.Color, opts ...Option) (Solution, error) {
+	c := newConfig(opts)
+	if err := c.checkBidirectional(); err != nil {
+		return nil, err
+	}
+	srch, err := s.search(ctx, robots, symbol, color, c)
+	if err != nil {
+		return nil, err
+	}
+	defer s.pool.Put(srch)
+	defer srch.done()
+	return srch.bidirectional()
+}
+
+// SolveBidirectional returns an optimal solution of the puzzle given by board, robot
+// positions and target using a bidirectional search (see Session.SolveBidirectional).
+func SolveBidirectional(ctx context.Context, b *board.Board, robots board.Robots, symbol board.Symbol, color board.Color, opts ...Option) (Solution, error) {
+	return NewSession(b).SolveBidirectional(ctx, robots, symbol, color, opts...)
+}
@@ -0,0 +1,335 @@
+// Package cache provides a persistent solution cache stored in a local directory.
+//
+// Solutions are stored in append-only segment files. Each record holds the
+// canonical key of a puzzle and a solution, prefixed by its length and a CRC-32
+// checksum. The index mapping keys to records is kept in memory and rebuilt by
+// scanning the segments when the cache is opened.
+//
+// A record is written by a single append, so a crash can at most leave a
+// damaged record at the end of the last segment. Opening the cache discards such
+// a record and all data following it.
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/solver"
+)
+
+// DefaultSegmentSize is the default size a segment file may grow to before a new
+// segment is started.
+const DefaultSegmentSize = 64 << 20
+
+const (
+	segmentExt       = ".seg"
+	recordHeaderSize = 4 + 2 // checksum, payload length
+	maxPayloadSize   = 1<<16 - 1
+)
+
+// ErrCorrupt is returned if cache data fails the checksum verification.
+var ErrCorrupt = errors.New("corrupt cache data")
+
+var errClosed = errors.New("cache closed")
+
+type location struct {
+	segment int
+	offset  int64
+}
+
+// Cache is a persistent solution cache. A cache is safe for concurrent use.
+type Cache struct {
+	dir         string
+	segmentSize int64
+
+	mu       sync.RWMutex
+	index    map[string]location
+	segments map[int]*os.File
+	active   int   // number of the segment appended to
+	size     int64 // size of the active segment
+}
+
+// Open opens the cache stored in directory dir, which is created if it does not exist.
+func Open(dir string) (*Cache, error) {
+	return open(dir, DefaultSegmentSize)
+}
+
+func open(dir string, segmentSize int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var numbers []int
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		var n int
+		if _, err := fmt.Sscanf(name, "%08d"+segmentExt, &n); err != nil {
+			return nil, fmt.Errorf("invalid segment file name %s", name)
+		}
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	c := &Cache{dir: dir, segmentSize: segmentSize, index: map[string]location{}, segments: map[int]*os.File{}}
+	for i, n := range numbers {
+		last := i == len(numbers)-1
+		if err := c.load(n, last); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	if len(numbers) == 0 {
+		if err := c.create(1); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *Cache) segmentPath(n int) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%08d%s", n, segmentExt))
+}
+
+// create creates and activates segment n.
+func (c *Cache) create(n int) error {
+	f, err := os.OpenFile(c.segmentPath(n), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	c.segments[n], c.active, c.size = f, n, 0
+	return nil
+}
+
+// load indexes the records of segment n. A damaged record in the last segment is
+// the result of an interrupted append: the segment is truncated before it.
+func (c *Cache) load(n int, last bool) error {
+	f, err := os.OpenFile(c.segmentPath(n), os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	c.segments[n] = f
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size()
+
+	offset := int64(0)
+	r := io.NewSectionReader(f, 0, size)
+	for offset < size {
+		key, _, recSize, err := readRecord(r, offset)
+		if err != nil {
+			if !last || !errors.Is(err, ErrCorrupt) {
+				return fmt.Errorf("segment %d offset %d: %w", n, offset, err)
+			}
+			if err := f.Truncate(offset); err != nil {
+				return err
+			}
+			break
+		}
+		c.index[string(key)] = location{segment: n, offset: offset}
+		offset += int64(recSize)
+	}
+	if last {
+		c.active, c.size = n, offset
+	}
+	return nil
+}
+
+// readRecord reads the record at offset and returns key, moves and record size.
+func readRecord(r io.ReaderAt, offset int64) ([]byte, []byte, int, error) {
+	var header [recordHeaderSize]byte
+	if _, err := r.ReadAt(header[:], offset); err != nil {
+		return nil, nil, 0, readError(err)
+	}
+	sum := binary.BigEndian.Uint32(header[0:])
+	payload := make([]byte, binary.BigEndian.Uint16(header[4:]))
+	if _, err := r.ReadAt(payload, offset+recordHeaderSize); err != nil {
+		return nil, nil, 0, readError(err)
+	}
+	if crc32.ChecksumIEEE(payload) != sum || len(payload) == 0 || int(payload[0])+1 > len(payload) {
+		return nil, nil, 0, ErrCorrupt
+	}
+	keyLen := int(payload[0])
+	return payload[1 : 1+keyLen], payload[1+keyLen:], recordHeaderSize + len(payload), nil
+}
+
+// readError returns ErrCorrupt for a record exceeding the end of the segment.
+func readError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrCorrupt
+	}
+	return err
+}
+
+// Close closes the segment files of the cache.
+func (c *Cache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var errs []error
+	for _, f := range c.segments {
+		errs = append(errs, f.Close())
+	}
+	c.segments = nil
+	return errors.Join(errs...)
+}
+
+// Sync commits the appended records to stable storage. Records not synced may be
+// lost on a crash of the system, but do not damage the cache.
+func (c *Cache) Sync() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.segments == nil {
+		return errClosed
+	}
+	return c.segments[c.active].Sync()
+}
+
+// Len returns the number of cached solutions.
+func (c *Cache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.index)
+}
+
+// Get returns the cached solution of the puzzle given by board, robot positions and target.
+func (c *Cache) Get(b *board.Board, robots board.Robots, symbol board.Symbol, color board.Color) (solver.Solution, bool, error) {
+	key, perm := canonicalKey(b, robots, symbol, color)
+
+	// the read lock is held while reading the record, so that Close cannot close the
+	// segment file in between; concurrent reads are fine as ReadAt does not share state
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.segments == nil {
+		return nil, false, errClosed
+	}
+	loc, ok := c.index[string(key)]
+	if !ok {
+		return nil, false, nil
+	}
+
+	_, moves, _, err := readRecord(c.segments[loc.segment], loc.offset)
+	if err != nil {
+		return nil, false, fmt.Errorf("segment %d offset %d: %w", loc.segment, loc.offset, err)
+	}
+	sol := make(solver.Solution, len(moves))
+	for i, m := range moves {
+		sol[i] = board.Move{Robot: board.RobotColors[perm[m>>2]], Direction: board.Direction(m & 0x03)}
+	}
+	return sol, true, nil
+}
+
+// Put stores solution sol of the puzzle given by board, robot positions and target.
+// If the puzzle is cached already, the cached solution is kept.
+func (c *Cache) Put(b *board.Board, robots board.Robots, symbol board.Symbol, color board.Color, sol solver.Solution) error {
+	key, perm := canonicalKey(b, robots, symbol, color)
+	var inv [board.NumRobot]byte // robot index -> canonical robot index
+	for i, j := range perm {
+		inv[j] = byte(i)
+	}
+	payload := make([]byte, 0, 1+len(key)+len(sol))
+	payload = append(payload, byte(len(key)))
+	payload = append(payload, key...)
+	for _, m := range sol {
+		i := board.RobotIndex(m.Robot)
+		if i == -1 || m.Direction >= board.NumDirection {
+			return fmt.Errorf("invalid move %s", m)
+		}
+		payload = append(payload, inv[i]<<2|byte(m.Direction))
+	}
+	if len(payload) > maxPayloadSize {
+		return fmt.Errorf("solution of %d moves too long", len(sol))
+	}
+	record := make([]byte, recordHeaderSize, recordHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(record[0:], crc32.ChecksumIEEE(payload))
+	binary.BigEndian.PutUint16(record[4:], uint16(len(payload)))
+	record = append(record, payload...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.segments == nil {
+		return errClosed
+	}
+	if _, ok := c.index[string(key)]; ok {
+		return nil
+	}
+	if c.size != 0 && c.size+int64(len(record)) > c.segmentSize {
+		if err := c.create(c.active + 1); err != nil {
+			return err
+		}
+	}
+	f := c.segments[c.active]
+	if _, err := f.WriteAt(record, c.size); err != nil {
+		return err
+	}
+	c.index[string(key)] = location{segment: c.active, offset: c.size}
+	c.size += int64(len(record))
+	return nil
+}
+
+// canonicalKey returns the canonical key of a puzzle and the permutation mapping the
+// canonical robot indices to the robot indices. The robots other than the target
+// robot (all robots for a cosmic target) are interchangeable, so their positions are
+// assigned to the canonical robot indices in ascending order.
+func canonicalKey(b *board.Board, robots board.Robots, symbol board.Symbol, color board.Color) ([]byte, [board.NumRobot]int) {
+	target := -1
+	if symbol != board.Cosmic {
+		target = board.RobotIndex(color)
+	}
+	var perm [board.NumRobot]int
+	var others []int
+	for i := range perm {
+		perm[i] = i
+		if i != target {
+			others = append(others, i)
+		}
+	}
+	sorted := append([]int(nil), others...)
+	sort.Slice(sorted, func(i, j int) bool { return robots[sorted[i]] < robots[sorted[j]] })
+	for k, i := range others {
+		perm[i] = sorted[k]
+	}
+
+	id := b.ID()
+	key := make([]byte, 0, len(id)+2+board.NumRobot)
+	key = append(key, id...)
+	if target == -1 {
+		color = 0
+	}
+	key = append(key, byte(symbol), byte(color))
+	for _, i := range perm {
+		key = append(key, robots[i])
+	}
+	return key, perm
+}
+
+// Solve returns the cached solution of the puzzle given by robot positions and target
+// on the board of session s. If the puzzle is not cached it is solved by the session
+// and the solution is stored.
+func (c *Cache) Solve(ctx context.Context, s *solver.Session, robots board.Robots, symbol board.Symbol, color board.Color) (solver.Solution, error) {
+	b := s.Board()
+	if sol, ok, err := c.Get(b, robots, symbol, color); err != nil || ok {
+		return sol, err
+	}
+	solutions, err := s.Solve(ctx, robots, symbol, color)
+	if err != nil {
+		return nil, err
+	}
+	return solutions[0], c.Put(b, robots, symbol, color, solutions[0])
+}
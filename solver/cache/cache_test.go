@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/internal/testutil"
+	"github.com/go-ricrob/game/solver"
+)
+
+// checkSolution checks that sol solves puzzle p with the given number of moves.
+func checkSolution(t *testing.T, b *board.Board, p testutil.Puzzle, sol solver.Solution, moves int) {
+	t.Helper()
+	testutil.CheckSolution(t, b, p, sol)
+	if len(sol) != moves {
+		t.Fatalf("%v: solution %s with %d moves - expected %d moves", p, sol, len(sol), moves)
+	}
+}
+
+// permuted returns the puzzle with the robots other than the target robot rotated.
+func permuted(p testutil.Puzzle) testutil.Puzzle {
+	target := board.RobotIndex(p.Color)
+	var others []int
+	for i := range p.Robots {
+		if i != target {
+			others = append(others, i)
+		}
+	}
+	q := p
+	for k, i := range others {
+		q.Robots[i] = p.Robots[others[(k+1)%len(others)]]
+	}
+	return q
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	b := board.New(testutil.DefaultTiles)
+	puzzles := testutil.RandomPuzzles(b, 10)
+	moves := make([]int, len(puzzles))
+
+	c, err := open(dir, 64) // small segments
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := solver.NewSession(b)
+	for i, p := range puzzles {
+		sol, err := c.Solve(context.Background(), session, p.Robots, p.Symbol, p.Color)
+		if err != nil {
+			t.Fatal(err)
+		}
+		moves[i] = len(sol)
+		checkSolution(t, b, p, sol, moves[i])
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	segments, _ := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if len(segments) < 2 {
+		t.Fatalf("%d segments - expected more than one", len(segments))
+	}
+
+	check := func(c *Cache, n int) {
+		t.Helper()
+		var wg sync.WaitGroup
+		for i, p := range puzzles[:n] {
+			wg.Add(1)
+			go func(i int, p testutil.Puzzle) {
+				defer wg.Done()
+				for _, q := range []testutil.Puzzle{p, permuted(p)} {
+					sol, ok, err := c.Get(b, q.Robots, q.Symbol, q.Color)
+					if err != nil || !ok {
+						t.Errorf("%v: get %t %v", q, ok, err)
+						return
+					}
+					checkSolution(t, b, q, sol, moves[i])
+				}
+			}(i, p)
+		}
+		wg.Wait()
+		if c.Len() != n {
+			t.Fatalf("%d cached solutions - expected %d", c.Len(), n)
+		}
+	}
+
+	c, err = open(dir, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(c, len(puzzles))
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// interrupted append: damaged last record
+	last := segments[len(segments)-1]
+	data, err := os.ReadFile(last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(last, data[:len(data)-1], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err = open(dir, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(c, len(puzzles)-1)
+	p := puzzles[len(puzzles)-1]
+	sol, err := c.Solve(context.Background(), session, p.Robots, p.Symbol, p.Color)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSolution(t, b, p, sol, moves[len(puzzles)-1])
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// damaged record in a segment other than the last one
+	first := segments[0]
+	data, err = os.ReadFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[recordHeaderSize] ^= 0x01
+	if err := os.WriteFile(first, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := open(dir, 64); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("open cache with damaged segment: error %v - expected %v", err, ErrCorrupt)
+	}
+}
+
+func TestCacheGetClose(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	p := testutil.RandomPuzzles(b, 1)[0]
+	c, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Solve(context.Background(), solver.NewSession(b), p.Robots, p.Symbol, p.Color); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				// a Get racing with Close either succeeds or reports the closed cache
+				_, ok, err := c.Get(b, p.Robots, p.Symbol, p.Color)
+				if errors.Is(err, errClosed) {
+					return
+				}
+				if err != nil || !ok {
+					t.Errorf("get %t %v", ok, err)
+					return
+				}
+			}
+		}()
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,204 @@
+package solver
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/coord"
+	"github.com/go-ricrob/game/internal/testutil"
+)
+
+const corpusFilename = "testdata/corpus.txt"
+
+type corpusPuzzle struct {
+	tiles [board.NumTile]string
+	testutil.Puzzle
+	moves int
+}
+
+func (p *corpusPuzzle) String() string {
+	return fmt.Sprintf("%v %s %s %v", p.tiles, p.Symbol, p.Color, p.Robots)
+}
+
+func parseColor(s string) (board.Color, error) {
+	if s == "-" {
+		return 0, nil
+	}
+	for _, c := range board.RobotColors {
+		if c.String() == s {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid color %s", s)
+}
+
+func parseSymbol(s string) (board.Symbol, error) {
+	for _, sym := range board.Symbols {
+		if sym.String() == s {
+			return sym, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid symbol %s", s)
+}
+
+func readCorpus(t testing.TB) []*corpusPuzzle {
+	f, err := os.Open(corpusFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var puzzles []*corpusPuzzle
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		fields := strings.Fields(line)
+		const numTile = int(board.NumTile)
+		if len(fields) != numTile+board.NumRobot+3 {
+			t.Fatalf("%s:%d: invalid number of columns %d", corpusFilename, lineNo, len(fields))
+		}
+		p := &corpusPuzzle{}
+		p.tiles[board.TopLeft], p.tiles[board.TopRight], p.tiles[board.BottomRight], p.tiles[board.BottomLeft] = fields[0], fields[1], fields[2], fields[3]
+		for i := range p.Robots {
+			var x, y int
+			if _, err := fmt.Sscanf(fields[numTile+i], "%d,%d", &x, &y); err != nil {
+				t.Fatalf("%s:%d: invalid robot position %s: %s", corpusFilename, lineNo, fields[numTile+i], err)
+			}
+			p.Robots[i] = coord.Ctob(x, y)
+		}
+		fields = fields[numTile+board.NumRobot:]
+		if p.Symbol, err = parseSymbol(fields[0]); err != nil {
+			t.Fatalf("%s:%d: %s", corpusFilename, lineNo, err)
+		}
+		if p.Color, err = parseColor(fields[1]); err != nil {
+			t.Fatalf("%s:%d: %s", corpusFilename, lineNo, err)
+		}
+		if _, err := fmt.Sscanf(fields[2], "%d", &p.moves); err != nil {
+			t.Fatalf("%s:%d: invalid number of moves %s: %s", corpusFilename, lineNo, fields[2], err)
+		}
+		puzzles = append(puzzles, p)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return puzzles
+}
+
+// shortCorpusMoves is the maximal number of moves of the corpus puzzles solved in short mode.
+const shortCorpusMoves = 10
+
+// TestCorpus fails if a solver returns a solution which is not optimal.
+func TestCorpus(t *testing.T) {
+	for _, p := range readCorpus(t) {
+		if testing.Short() && p.moves > shortCorpusMoves {
+			continue
+		}
+		b := board.New(p.tiles)
+		solutions, err := Solve(context.Background(), b, p.Robots, p.Symbol, p.Color)
+		if err != nil {
+			t.Fatalf("%s: %s", p, err)
+		}
+		testutil.CheckSolution(t, b, p.Puzzle, solutions[0])
+		if len(solutions[0]) != p.moves {
+			t.Fatalf("%s: solution %s with %d moves - expected %d moves", p, solutions[0], len(solutions[0]), p.moves)
+		}
+		if p.moves <= shortCorpusMoves+2 {
+			sol, err := SolveBidirectional(context.Background(), b, p.Robots, p.Symbol, p.Color)
+			if err != nil {
+				t.Fatalf("%s: %s", p, err)
+			}
+			testutil.CheckSolution(t, b, p.Puzzle, sol)
+			if len(sol) != p.moves {
+				t.Fatalf("%s: bidirectional solution %s with %d moves - expected %d moves", p, sol, len(sol), p.moves)
+			}
+		}
+		sol, ok, err := Within(context.Background(), b, p.Robots, p.Symbol, p.Color, p.moves)
+		if err != nil || !ok {
+			t.Fatalf("%s: within %d moves %t %v - expected true", p, p.moves, ok, err)
+		}
+		testutil.CheckSolution(t, b, p.Puzzle, sol)
+	}
+}
+
+func BenchmarkCorpus(b *testing.B) {
+	for _, p := range readCorpus(b) {
+		if p.moves > shortCorpusMoves+2 {
+			continue
+		}
+		brd := board.New(p.tiles)
+		b.Run(fmt.Sprintf("%02dmoves/%s%s", p.moves, p.Symbol, p.Color), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := Solve(context.Background(), brd, p.Robots, p.Symbol, p.Color); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkBidirectional compares the forward and the bidirectional search on the deep corpus puzzles.
+func BenchmarkBidirectional(b *testing.B) {
+	for _, p := range readCorpus(b) {
+		if p.moves < shortCorpusMoves || p.moves > shortCorpusMoves+2 {
+			continue
+		}
+		session := NewSession(board.New(p.tiles))
+		name := fmt.Sprintf("%02dmoves/%s%s", p.moves, p.Symbol, p.Color)
+		b.Run(name+"/forward", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := session.Solve(context.Background(), p.Robots, p.Symbol, p.Color); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(name+"/bidirectional", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := session.SolveBidirectional(context.Background(), p.Robots, p.Symbol, p.Color); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkSymmetry compares the states expanded with and without the robot permutation
+// symmetry reduction.
+func BenchmarkSymmetry(b *testing.B) {
+	for _, p := range readCorpus(b) {
+		if p.moves > shortCorpusMoves+2 {
+			continue
+		}
+		brd := board.New(p.tiles)
+		name := fmt.Sprintf("%02dmoves/%s%s", p.moves, p.Symbol, p.Color)
+		for _, noSymmetry := range []bool{false, true} {
+			mode := "symmetry"
+			if noSymmetry {
+				mode = "nosymmetry"
+			}
+			c := newConfig(nil)
+			c.noSymmetry = noSymmetry
+			b.Run(name+"/"+mode, func(b *testing.B) {
+				expanded := 0
+				for i := 0; i < b.N; i++ {
+					s := &search{}
+					if err := s.init(context.Background(), brd, p.Robots, p.Symbol, p.Color, c); err != nil {
+						b.Fatal(err)
+					}
+					if _, err := s.run(); err != nil {
+						b.Fatal(err)
+					}
+					expanded += s.expanded
+				}
+				b.ReportMetric(float64(expanded)/float64(b.N), "states/op")
+			})
+		}
+	}
+}
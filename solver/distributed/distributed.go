@@ -0,0 +1,297 @@
+// Package distributed provides solving of puzzle batches by worker processes.
+//
+// A coordinator serves the jobs of a batch via net/rpc. Workers connect to the
+// coordinator, request jobs one at a time and return solution and search
+// statistics of each job. If the connection of a worker is lost or the worker does
+// not return the result of a job within the job timeout, the job is handed out
+// again.
+package distributed
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/solver"
+)
+
+const serviceName = "Coordinator"
+
+// DefaultTimeout is the default job timeout of a coordinator.
+const DefaultTimeout = 10 * time.Minute
+
+// Job is a puzzle to be solved.
+type Job struct {
+	Tiles  [board.NumTile]string
+	Robots board.Robots
+	Symbol board.Symbol
+	Color  board.Color
+}
+
+// Result is the result of a job.
+type Result struct {
+	Job      Job
+	Solution solver.Solution
+	Stats    solver.Stats
+	Err      string // error message, empty on success
+}
+
+// Assignment is the reply of the Next call.
+type Assignment struct {
+	ID  int
+	Job Job
+	OK  bool // false: no jobs left
+}
+
+// Coordinator hands out jobs to workers and collects the results.
+type Coordinator struct {
+	// Timeout is the time a worker has to return the result of a job before the job
+	// is handed out again (0: no timeout). A result returned late is still accepted
+	// if the job is not done by then. Timeout must not be changed after Serve is called.
+	Timeout time.Duration
+
+	mu        sync.Mutex
+	cond      *sync.Cond
+	jobs      []Job
+	queue     []int // IDs of jobs to be handed out
+	results   []Result
+	done      []bool
+	remaining int
+	finished  chan struct{}
+}
+
+// NewCoordinator returns a new coordinator for jobs.
+func NewCoordinator(jobs []Job) *Coordinator {
+	c := &Coordinator{
+		Timeout:   DefaultTimeout,
+		jobs:      jobs,
+		queue:     make([]int, len(jobs)),
+		results:   make([]Result, len(jobs)),
+		done:      make([]bool, len(jobs)),
+		remaining: len(jobs),
+		finished:  make(chan struct{}),
+	}
+	c.cond = sync.NewCond(&c.mu)
+	for i := range c.queue {
+		c.queue[i] = i
+	}
+	if len(jobs) == 0 {
+		close(c.finished)
+	}
+	return c
+}
+
+// Serve accepts worker connections on listener l until l is closed.
+func (c *Coordinator) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		w := &worker{c: c, assigned: map[int]*assignment{}}
+		srv := rpc.NewServer()
+		if err := srv.RegisterName(serviceName, w); err != nil {
+			conn.Close()
+			return err
+		}
+		go c.serveConn(conn, srv, w)
+	}
+}
+
+// serveConn serves the connection of worker w and requeues the jobs of the worker
+// not done when the connection ends.
+func (c *Coordinator) serveConn(conn net.Conn, srv *rpc.Server, w *worker) {
+	srv.ServeConn(conn)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, a := range w.assigned {
+		c.release(id, a)
+	}
+	c.cond.Broadcast()
+}
+
+// release stops the timer of assignment a of job id and requeues the job if it is
+// neither done nor requeued already. c.mu must be held.
+func (c *Coordinator) release(id int, a *assignment) {
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	if !a.requeued && !c.done[id] {
+		a.requeued = true
+		c.queue = append(c.queue, id)
+	}
+}
+
+// expire requeues job id when assignment a times out.
+func (c *Coordinator) expire(id int, a *assignment) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.release(id, a)
+	c.cond.Broadcast()
+}
+
+// Wait waits until the results of all jobs are available and returns them in the
+// order of the jobs.
+func (c *Coordinator) Wait(ctx context.Context) ([]Result, error) {
+	select {
+	case <-c.finished:
+		return c.results, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// worker is the RPC service of a worker connection.
+type worker struct {
+	c        *Coordinator
+	assigned map[int]*assignment // assigned jobs by ID, guarded by c.mu
+}
+
+// assignment is a job assigned to a worker.
+type assignment struct {
+	timer    *time.Timer // job timeout, nil if none
+	requeued bool        // job handed out again
+}
+
+// Next hands out the next job. If all jobs are handed out but not done, Next waits
+// for a job to be requeued or the last job to be done.
+func (w *worker) Next(_ int, a *Assignment) error {
+	c := w.c
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for {
+		for len(c.queue) != 0 && c.done[c.queue[0]] {
+			c.queue = c.queue[1:]
+		}
+		if len(c.queue) != 0 || c.remaining == 0 {
+			break
+		}
+		c.cond.Wait()
+	}
+	if c.remaining == 0 {
+		*a = Assignment{}
+		return nil
+	}
+	id := c.queue[0]
+	c.queue = c.queue[1:]
+	if prev, ok := w.assigned[id]; ok && prev.timer != nil { // job handed out again to the same worker
+		prev.timer.Stop()
+	}
+	as := &assignment{}
+	if c.Timeout > 0 {
+		as.timer = time.AfterFunc(c.Timeout, func() { c.expire(id, as) })
+	}
+	w.assigned[id] = as
+	*a = Assignment{ID: id, Job: c.jobs[id], OK: true}
+	return nil
+}
+
+// Done returns the result of job id. The job needs to be assigned to the worker.
+func (w *worker) Done(r *DoneArgs, _ *int) error {
+	c := w.c
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if r.ID < 0 || r.ID >= len(c.jobs) {
+		return fmt.Errorf("invalid job %d", r.ID)
+	}
+	a, ok := w.assigned[r.ID]
+	if !ok {
+		return fmt.Errorf("job %d not assigned to worker", r.ID)
+	}
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	delete(w.assigned, r.ID)
+	if c.done[r.ID] { // requeued job done twice
+		return nil
+	}
+	c.done[r.ID] = true
+	c.results[r.ID] = r.Result
+	c.results[r.ID].Job = c.jobs[r.ID]
+	if c.remaining--; c.remaining == 0 {
+		close(c.finished)
+		c.cond.Broadcast()
+	}
+	return nil
+}
+
+// DoneArgs are the arguments of the Done call.
+type DoneArgs struct {
+	ID     int
+	Result Result
+}
+
+// RunWorker connects to the coordinator at address addr and solves jobs until all
+// jobs are done or the context is done.
+func RunWorker(ctx context.Context, addr string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	client := rpc.NewClient(conn)
+	defer client.Close()
+
+	sessions := map[[board.NumTile]string]*solver.Session{}
+	for {
+		var a Assignment
+		if err := call(ctx, client, "Next", 0, &a); err != nil {
+			return err
+		}
+		if !a.OK {
+			return nil
+		}
+		var r Result
+		s, ok := sessions[a.Job.Tiles]
+		if !ok {
+			if b, err := board.NewFromTiles(a.Job.Tiles); err != nil {
+				r = Result{Job: a.Job, Err: err.Error()}
+			} else {
+				s = solver.NewSession(b)
+				sessions[a.Job.Tiles] = s
+			}
+		}
+		if s != nil {
+			r = solve(ctx, s, a.Job)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := call(ctx, client, "Done", &DoneArgs{ID: a.ID, Result: r}, new(int)); err != nil {
+			return err
+		}
+	}
+}
+
+// call executes a remote call which is abandoned if the context is done.
+func call(ctx context.Context, client *rpc.Client, method string, args, reply any) error {
+	c := client.Go(serviceName+"."+method, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-c.Done:
+		return c.Error
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type statsObserver struct{ stats solver.Stats }
+
+func (o *statsObserver) Progress(solver.Stats)   {}
+func (o *statsObserver) Done(stats solver.Stats) { o.stats = stats }
+
+func solve(ctx context.Context, s *solver.Session, job Job) Result {
+	o := &statsObserver{}
+	solutions, err := s.Solve(ctx, job.Robots, job.Symbol, job.Color, solver.WithObserver(o))
+	r := Result{Job: job, Stats: o.stats}
+	if err != nil {
+		r.Err = err.Error()
+	} else {
+		r.Solution = solutions[0]
+	}
+	return r
+}
@@ -0,0 +1,171 @@
+package distributed
+
+import (
+	"context"
+	"net"
+	"net/rpc"
+	"testing"
+	"time"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/internal/testutil"
+)
+
+func randomJobs(b *board.Board, n int) []Job {
+	var jobs []Job
+	for _, p := range testutil.RandomPuzzles(b, n) {
+		jobs = append(jobs, Job{Tiles: testutil.DefaultTiles, Robots: p.Robots, Symbol: p.Symbol, Color: p.Color})
+	}
+	return jobs
+}
+
+func TestDistributed(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	jobs := randomJobs(b, 20)
+	c := NewCoordinator(jobs)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go c.Serve(l)
+	addr := l.Addr().String()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// crashing worker: takes jobs without returning results
+	client, err := rpc.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		var a Assignment
+		if err := client.Call(serviceName+".Next", 0, &a); err != nil || !a.OK {
+			t.Fatalf("next: %v %v", a, err)
+		}
+	}
+	client.Close()
+
+	errs := make(chan error, 3)
+	for i := 0; i < cap(errs); i++ {
+		go func() { errs <- RunWorker(ctx, addr) }()
+	}
+	results, err := c.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i, r := range results {
+		if r.Job != jobs[i] {
+			t.Fatalf("result %d: job %v - expected %v", i, r.Job, jobs[i])
+		}
+		if r.Err != "" {
+			t.Fatalf("job %v: %s", r.Job, r.Err)
+		}
+		robots := r.Job.Robots
+		for _, m := range r.Solution {
+			robots = b.Move(robots, m)
+		}
+		target := b.TargetCoord(r.Job.Symbol, r.Job.Color)
+		if i := board.RobotIndex(r.Job.Color); i != -1 && robots[i] != target || i == -1 && !robots.Occupied(target) {
+			t.Fatalf("job %v: solution %s does not reach target", r.Job, r.Solution)
+		}
+		if r.Stats.Expanded == 0 && len(r.Solution) != 0 {
+			t.Fatalf("job %v: no statistics", r.Job)
+		}
+	}
+}
+
+// serve starts serving coordinator c and returns the listener address.
+func serve(t *testing.T, c *Coordinator) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go c.Serve(l)
+	return l.Addr().String()
+}
+
+func TestDistributedTimeout(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	jobs := randomJobs(b, 5)
+	c := NewCoordinator(jobs)
+	c.Timeout = 100 * time.Millisecond
+	addr := serve(t, c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// hanging worker: takes a job and keeps the connection without returning the result
+	client, err := rpc.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var a Assignment
+	if err := client.Call(serviceName+".Next", 0, &a); err != nil || !a.OK {
+		t.Fatalf("next: %v %v", a, err)
+	}
+
+	if err := RunWorker(ctx, addr); err != nil {
+		t.Fatal(err)
+	}
+	results, err := c.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Err != "" {
+			t.Fatalf("job %v: %s", r.Job, r.Err)
+		}
+	}
+	// late result of the hanging worker
+	if err := client.Call(serviceName+".Done", &DoneArgs{ID: a.ID}, new(int)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDistributedErrors(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	job := randomJobs(b, 1)[0]
+	invalidTiles, invalidTarget := job, job
+	invalidTiles.Tiles[0] = "X"
+	invalidTarget.Symbol, invalidTarget.Color = board.Cosmic, board.Red
+	c := NewCoordinator([]Job{invalidTiles, invalidTarget, job})
+	addr := serve(t, c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	client, err := rpc.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	for _, id := range []int{-1, 3, 0} { // out of range and not assigned
+		if err := client.Call(serviceName+".Done", &DoneArgs{ID: id}, new(int)); err == nil {
+			t.Fatalf("done of job %d: error expected", id)
+		}
+	}
+
+	if err := RunWorker(ctx, addr); err != nil {
+		t.Fatal(err)
+	}
+	results, err := c.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range results {
+		if (r.Err != "") != (i != 2) {
+			t.Fatalf("job %v: error %q", r.Job, r.Err)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package solver
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/go-ricrob/game/board"
+)
+
+// Heuristic is the interface of estimates the solver combines with the min moves
+// estimate by taking the maximum of both.
+type Heuristic interface {
+	// Estimate returns the estimated number of moves the robots located at r need
+	// until robot i (-1: any robot) reaches the target field, -1 if the target
+	// field is unreachable. Returning 0 means that no estimate is available.
+	Estimate(r *board.Robots, target byte, i int) int
+}
+
+// WithHeuristic makes the solver use heuristic h in addition to the min moves estimate.
+// If h is not a lower bound of the number of moves the solutions are not guaranteed
+// to be optimal. A PatternDB of another board results in an ErrPatternDBBoard error.
+func WithHeuristic(h Heuristic) Option { return func(c *config) { c.heuristic = h } }
+
+const (
+	pdbNumState   = board.NumField * board.NumField
+	pdbUnreached  = 0xff
+	pdbMagic      = "RRPD"
+	pdbVersion    = 1
+	pdbHeaderSize = len(pdbMagic) + 1
+)
+
+// pdbTable holds the number of moves of the target robot plus one helper robot for all
+// combinations of target robot (high byte) and helper (low byte) positions.
+type pdbTable [pdbNumState]byte
+
+// PatternDB is a pattern database heuristic. For each colored target it holds the
+// number of moves needed by the target robot together with one helper robot for all
+// positions of both robots. The estimate for a state is the maximum over all helpers.
+//
+// To keep the estimate a lower bound the remaining robots are taken into account as
+// potential blockers everywhere: a moving robot can stop on any field on its way
+// before the next wall or the other robot. Therefore, unlike min moves, the pattern
+// database knows about the helper blocking the target robot's way, like a helper
+// located on the target field.
+type PatternDB struct {
+	boardID string
+	tables  [board.NumField]*pdbTable // indexed by target field
+}
+
+// NewPatternDB calculates the pattern database of all colored targets of board b.
+func NewPatternDB(b *board.Board) *PatternDB {
+	db := &PatternDB{boardID: b.ID()}
+	for c, f := range b.Fields {
+		if f.Symbol != board.NoSymbol && f.Symbol != board.Cosmic {
+			db.tables[c] = newPDBTable(b, byte(c))
+		}
+	}
+	return db
+}
+
+// newPDBTable calculates the table of target field t by a backward breadth first search.
+func newPDBTable(b *board.Board, t byte) *pdbTable {
+	table := &pdbTable{}
+	for i := range table {
+		table[i] = pdbUnreached
+	}
+	queue := make([]uint16, 0, pdbNumState)
+	for h := 0; h < board.NumField; h++ {
+		if byte(h) != t {
+			s := uint16(t)<<8 | uint16(h)
+			table[s] = 0
+			queue = append(queue, s)
+		}
+	}
+	visit := func(s uint16, dist byte) {
+		if table[s] == pdbUnreached {
+			table[s] = dist
+			queue = append(queue, s)
+		}
+	}
+	for len(queue) != 0 {
+		s := queue[0]
+		queue = queue[1:]
+		c, h := byte(s>>8), byte(s)
+		dist := table[s] + 1
+		for _, d := range board.Directions {
+			// target robot moved last from a field between c and the next wall or the helper
+			for src, stop := c, b.Stops[c][d]; src != stop; {
+				if src = step(src, d); src == h {
+					break
+				}
+				visit(uint16(src)<<8|uint16(h), dist)
+			}
+			// helper moved last from a field between h and the next wall or the target robot
+			for src, stop := h, b.Stops[h][d]; src != stop; {
+				if src = step(src, d); src == c {
+					break
+				}
+				visit(uint16(c)<<8|uint16(src), dist)
+			}
+		}
+	}
+	return table
+}
+
+// step returns the neighbor field of field c in direction d.
+func step(c byte, d board.Direction) byte {
+	switch d {
+	case board.North:
+		return c + 1
+	case board.East:
+		return c + 1<<4
+	case board.South:
+		return c - 1
+	default:
+		return c - 1<<4
+	}
+}
+
+// BoardID returns the ID of the board the pattern database belongs to.
+func (db *PatternDB) BoardID() string { return db.boardID }
+
+// Estimate implements the Heuristic interface.
+func (db *PatternDB) Estimate(r *board.Robots, target byte, i int) int {
+	table := db.tables[target]
+	if table == nil || i == -1 {
+		return 0
+	}
+	t := uint16(r[i]) << 8
+	h := 0
+	for j, c := range r {
+		if j == i {
+			continue
+		}
+		d := table[t|uint16(c)]
+		if d == pdbUnreached {
+			return -1
+		}
+		if int(d) > h {
+			h = int(d)
+		}
+	}
+	return h
+}
+
+// File format: magic, version, board ID length and board ID, number of tables,
+// per table the target field and the table data, and the CRC-32 (IEEE) checksum
+// of all preceding bytes. Numbers are stored in big endian byte order.
+
+// WriteTo writes the pattern database to w.
+func (db *PatternDB) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	crc := crc32.NewIEEE()
+	bw := bufio.NewWriter(io.MultiWriter(cw, crc))
+
+	bw.WriteString(pdbMagic)
+	bw.WriteByte(pdbVersion)
+	bw.WriteByte(byte(len(db.boardID)))
+	bw.WriteString(db.boardID)
+	n := 0
+	for _, table := range db.tables {
+		if table != nil {
+			n++
+		}
+	}
+	bw.WriteByte(byte(n))
+	for c, table := range db.tables {
+		if table != nil {
+			bw.WriteByte(byte(c))
+			bw.Write(table[:])
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return cw.n, err
+	}
+	err := binary.Write(cw, binary.BigEndian, crc.Sum32())
+	return cw.n, err
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// ErrPatternDBBoard is returned by ReadPatternDB and by the solver if the pattern
+// database belongs to another board.
+var ErrPatternDBBoard = errors.New("pattern database of different board")
+
+// ReadPatternDB reads a pattern database written by WriteTo for board b.
+func ReadPatternDB(r io.Reader, b *board.Board) (*PatternDB, error) {
+	crc := crc32.NewIEEE()
+	br := bufio.NewReader(r)
+	tr := io.TeeReader(br, crc)
+
+	header := make([]byte, pdbHeaderSize)
+	if _, err := io.ReadFull(tr, header); err != nil {
+		return nil, err
+	}
+	if string(header[:len(pdbMagic)]) != pdbMagic {
+		return nil, errors.New("invalid pattern database file")
+	}
+	if v := header[len(pdbMagic)]; v != pdbVersion {
+		return nil, fmt.Errorf("unsupported pattern database version %d", v)
+	}
+	var idLen [1]byte
+	if _, err := io.ReadFull(tr, idLen[:]); err != nil {
+		return nil, err
+	}
+	id := make([]byte, idLen[0])
+	if _, err := io.ReadFull(tr, id); err != nil {
+		return nil, err
+	}
+	if string(id) != b.ID() {
+		return nil, ErrPatternDBBoard
+	}
+	db := &PatternDB{boardID: string(id)}
+	var n [1]byte
+	if _, err := io.ReadFull(tr, n[:]); err != nil {
+		return nil, err
+	}
+	for i := 0; i < int(n[0]); i++ {
+		var c [1]byte
+		if _, err := io.ReadFull(tr, c[:]); err != nil {
+			return nil, err
+		}
+		table := &pdbTable{}
+		if _, err := io.ReadFull(tr, table[:]); err != nil {
+			return nil, err
+		}
+		db.tables[c[0]] = table
+	}
+	sum := crc.Sum32()
+	var stored uint32
+	if err := binary.Read(br, binary.BigEndian, &stored); err != nil {
+		return nil, err
+	}
+	if stored != sum {
+		return nil, errors.New("pattern database checksum mismatch")
+	}
+	return db, nil
+}
@@ -0,0 +1,90 @@
+package solver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/internal/testutil"
+)
+
+func TestPatternDB(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	db := NewPatternDB(b)
+
+	n := 20
+	if testing.Short() {
+		n = 5
+	}
+	for _, p := range testutil.RandomPuzzles(b, n) {
+		solutions, err := Solve(context.Background(), b, p.Robots, p.Symbol, p.Color, WithHeuristic(db))
+		if err != nil {
+			t.Fatal(err)
+		}
+		testutil.CheckSolution(t, b, p, solutions[0])
+		opt := bfs(b, p)
+		if len(solutions[0]) != opt {
+			t.Fatalf("%v: solution %s with %d moves - expected %d moves", p, solutions[0], len(solutions[0]), opt)
+		}
+		if p.Symbol == board.Cosmic {
+			continue
+		}
+		i := board.RobotIndex(p.Color)
+		target := b.TargetCoord(p.Symbol, p.Color)
+		if e := db.Estimate(&p.Robots, target, i); e > opt {
+			t.Fatalf("%v: estimate %d exceeds optimum %d", p, e, opt)
+		}
+		if e, m := db.Estimate(&p.Robots, target, i), b.TargetMinMoves(p.Symbol, p.Color)[p.Robots[i]]; e < m {
+			t.Fatalf("%v: estimate %d below min moves %d", p, e, m)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := db.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	db2, err := ReadPatternDB(bytes.NewReader(data), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db2.BoardID() != db.BoardID() || !equalTables(db, db2) {
+		t.Fatal("pattern database read differs from pattern database written")
+	}
+
+	other := board.New([board.NumTile]string{
+		board.TopLeft:     "A2F",
+		board.TopRight:    "A3F",
+		board.BottomRight: "A4F",
+		board.BottomLeft:  "A1F",
+	})
+	if _, err := ReadPatternDB(bytes.NewReader(data), other); !errors.Is(err, ErrPatternDBBoard) {
+		t.Fatalf("read pattern database of other board: error %v - expected %v", err, ErrPatternDBBoard)
+	}
+	p := testutil.RandomPuzzles(other, 1)[0]
+	if _, err := Solve(context.Background(), other, p.Robots, p.Symbol, p.Color, WithHeuristic(db)); !errors.Is(err, ErrPatternDBBoard) {
+		t.Fatalf("solve with pattern database of other board: error %v - expected %v", err, ErrPatternDBBoard)
+	}
+
+	corrupt := bytes.Clone(data)
+	corrupt[len(corrupt)/2] ^= 0x01
+	if _, err := ReadPatternDB(bytes.NewReader(corrupt), b); err == nil {
+		t.Fatal("read corrupt pattern database: error expected")
+	}
+	if _, err := ReadPatternDB(bytes.NewReader(data[:len(data)-1]), b); err == nil {
+		t.Fatal("read truncated pattern database: error expected")
+	}
+}
+
+func equalTables(db1, db2 *PatternDB) bool {
+	for i := range db1.tables {
+		t1, t2 := db1.tables[i], db2.tables[i]
+		if (t1 == nil) != (t2 == nil) || t1 != nil && *t1 != *t2 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package solver
+
+import "time"
+
+// Stats are statistics of a search.
+type Stats struct {
+	Depth           int           // depth reached: depth first search iteration, best first search moves or forward search level
+	Expanded        int           // number of states expanded
+	TableSize       int           // number of states stored in the transposition table
+	BranchingFactor float64       // average number of successors of an expanded state
+	Elapsed         time.Duration // time since the start of the search
+}
+
+// Observer is the interface of search observers.
+type Observer interface {
+	// Progress is called periodically during the search: at the start of every
+	// iteration of the depth first search or level of the bidirectional search and
+	// every 16384 expanded states.
+	Progress(stats Stats)
+	// Done is called once with the final statistics when the search ends.
+	Done(stats Stats)
+}
+
+// WithObserver attaches observer o to the search. Without an observer no statistics
+// are collected.
+func WithObserver(o Observer) Option { return func(c *config) { c.observer = o } }
+
+func (s *search) stats() Stats {
+	stats := Stats{
+		Depth:     s.depth,
+		Expanded:  s.expanded,
+		TableSize: len(s.seen) + len(s.best),
+		Elapsed:   time.Since(s.startTime),
+	}
+	if s.expanded != 0 {
+		stats.BranchingFactor = float64(s.generated) / float64(s.expanded)
+	}
+	return stats
+}
+
+func (s *search) progress() {
+	if s.observer != nil {
+		s.observer.Progress(s.stats())
+	}
+}
+
+func (s *search) done() {
+	if s.observer != nil {
+		s.observer.Done(s.stats())
+	}
+}
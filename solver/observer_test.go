@@ -0,0 +1,68 @@
+package solver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-ricrob/game/board"
+)
+
+type recorder struct {
+	progress []Stats
+	done     []Stats
+}
+
+func (r *recorder) Progress(stats Stats) { r.progress = append(r.progress, stats) }
+func (r *recorder) Done(stats Stats)     { r.done = append(r.done, stats) }
+
+func TestObserver(t *testing.T) {
+	var deep *corpusPuzzle
+	for _, p := range readCorpus(t) {
+		if p.moves == shortCorpusMoves {
+			deep = p
+			break
+		}
+	}
+	b := board.New(deep.tiles)
+
+	solvers := map[string]func(o Observer) error{
+		"solve": func(o Observer) error {
+			_, err := Solve(context.Background(), b, deep.Robots, deep.Symbol, deep.Color, WithObserver(o))
+			return err
+		},
+		"within": func(o Observer) error {
+			_, _, err := Within(context.Background(), b, deep.Robots, deep.Symbol, deep.Color, deep.moves, WithObserver(o))
+			return err
+		},
+		"bidirectional": func(o Observer) error {
+			_, err := SolveBidirectional(context.Background(), b, deep.Robots, deep.Symbol, deep.Color, WithObserver(o))
+			return err
+		},
+		"anytime": func(o Observer) error {
+			return Anytime(context.Background(), b, deep.Robots, deep.Symbol, deep.Color, func(Result) {}, WithObserver(o))
+		},
+	}
+	for name, solve := range solvers {
+		r := &recorder{}
+		if err := solve(r); err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if len(r.done) != 1 {
+			t.Fatalf("%s: done called %d times - expected once", name, len(r.done))
+		}
+		stats := r.done[0]
+		if stats.Expanded == 0 || stats.TableSize == 0 || stats.BranchingFactor <= 1 || stats.Depth == 0 || stats.Elapsed <= 0 {
+			t.Fatalf("%s: invalid final statistics %+v", name, stats)
+		}
+		prev := Stats{}
+		for _, p := range append(r.progress, stats) {
+			if p.Expanded < prev.Expanded || p.Elapsed < prev.Elapsed {
+				t.Fatalf("%s: statistics %+v after %+v", name, p, prev)
+			}
+			prev = p
+		}
+		if name == "solve" && (len(r.progress) == 0 || stats.Depth != deep.moves) {
+			t.Fatalf("%s: %d progress events, depth %d - expected depth %d", name, len(r.progress), stats.Depth, deep.moves)
+		}
+	}
+}
@@ -0,0 +1,403 @@
+package solver
+
+import (
+	"context"
+	"math/bits"
+	"time"
+
+	"github.com/go-ricrob/game/board"
+)
+
+const ctxCheckInterval = 1 << 14 // number of expanded nodes between context checks
+
+type search struct {
+	ctx       context.Context
+	b         *board.Board
+	start     board.Robots
+	target    byte // target field
+	robot     int  // target robot index, -1: any robot
+	minMoves  *[board.NumField]int
+	heuristic Heuristic // nil: min moves only
+	all       bool
+	maxDepth  int
+	symmetric bool // robots other than the target robot are interchangeable
+
+	// constraints
+	allowed   [board.NumRobot]bool
+	maxRobots int                 // 0: no limit
+	forbidden map[board.Move]bool // forbidden first moves
+
+	seen      map[uint64]int // state -> maximal number of moves known not to reach the target
+	path      []board.Move
+	solutions []Solution
+	expanded  int // number of expanded states
+	err       error
+
+	// statistics, collected only if an observer is attached
+	observer  Observer
+	startTime time.Time
+	depth     int
+	generated int // number of successors of expanded states
+
+	// bounded search
+	nodes []node
+	best  map[uint64]int // state -> fewest moves reached
+	open  queue
+}
+
+// init prepares the search for a puzzle reusing the memory of a previous search.
+func (s *search) init(ctx context.Context, b *board.Board, robots board.Robots, symbol board.Symbol, color board.Color, c *config) error {
+	robot, err := targetRobot(b, symbol, color)
+	if err != nil {
+		return err
+	}
+	if db, ok := c.heuristic.(*PatternDB); ok && db.boardID != b.ID() {
+		return ErrPatternDBBoard
+	}
+	s.ctx, s.b, s.start = ctx, b, robots
+	s.target, s.robot, s.minMoves = b.TargetCoord(symbol, color), robot, b.TargetMinMoves(symbol, color)
+	s.heuristic, s.all, s.maxDepth = c.heuristic, c.all, c.maxDepth
+	for i, color := range board.RobotColors {
+		s.allowed[i] = c.allowed == nil || c.allowed[color]
+	}
+	s.maxRobots, s.forbidden = c.maxRobots, c.forbidden
+	s.symmetric = !c.noSymmetry
+	for i := range s.allowed {
+		if i != robot && s.allowed[i] != s.allowed[(robot+1)%board.NumRobot] {
+			s.symmetric = false // interchangeable only if equally allowed
+		}
+	}
+
+	if s.seen == nil {
+		s.seen, s.best = map[uint64]int{}, map[uint64]int{}
+	} else {
+		clear(s.seen)
+		clear(s.best)
+	}
+	s.path, s.solutions, s.expanded, s.err = s.path[:0], nil, 0, nil
+	s.observer, s.depth, s.generated = c.observer, 0, 0
+	if s.observer != nil {
+		s.startTime = time.Now()
+	}
+	s.nodes, s.open = s.nodes[:0], s.open[:0]
+	return nil
+}
+
+// state is a search state: the robot positions and, if the number of robots moved
+// is limited, the set of robots moved so far.
+type state struct {
+	robots board.Robots
+	moved  uint8 // bit i set: robot i moved
+}
+
+func (st *state) key() uint64 {
+	r := &st.robots
+	return uint64(r[0]) | uint64(r[1])<<8 | uint64(r[2])<<16 | uint64(r[3])<<24 | uint64(st.moved)<<32
+}
+
+// key returns the key of state st in the search tables. If the robots other than the
+// target robot are interchangeable, their positions are sorted, so that all states
+// differing only in a permutation of these robots share one key.
+func (s *search) key(st *state) uint64 {
+	if !s.symmetric {
+		return st.key()
+	}
+	var v [board.NumRobot]uint64 // position and moved flag of interchangeable robots
+	n := 0
+	k := uint64(0)
+	for i, c := range st.robots {
+		x := uint64(c)<<1 | uint64(st.moved>>i&1)
+		if i == s.robot {
+			k = x
+			continue
+		}
+		// insertion sort
+		j := n
+		for ; j > 0 && v[j-1] > x; j-- {
+			v[j] = v[j-1]
+		}
+		v[j] = x
+		n++
+	}
+	for _, x := range v[:n] {
+		k = k<<9 | x
+	}
+	return k
+}
+
+// estimated moves needed to reach the target; -1 if unreachable
+func (s *search) estimate(r *board.Robots) int {
+	h := s.minMovesEstimate(r)
+	if h > 0 && s.heuristic != nil {
+		e := s.heuristic.Estimate(r, s.target, s.robot)
+		if e == -1 {
+			return -1
+		}
+		h = max(h, e)
+	}
+	return h
+}
+
+// lower bound of moves needed to reach the target; -1 if unreachable
+func (s *search) minMovesEstimate(r *board.Robots) int {
+	if s.robot != -1 {
+		if !s.allowed[s.robot] && r[s.robot] != s.target {
+			return -1
+		}
+		return s.minMoves[r[s.robot]]
+	}
+	h := -1
+	for i, c := range r {
+		if !s.allowed[i] {
+			continue
+		}
+		if m := s.minMoves[c]; m != -1 && (h == -1 || m < h) {
+			h = m
+		}
+	}
+	return h
+}
+
+func (s *search) isGoal(r *board.Robots) bool {
+	if s.robot != -1 {
+		return r[s.robot] == s.target
+	}
+	for i, c := range r {
+		if s.allowed[i] && c == s.target {
+			return true
+		}
+	}
+	return false
+}
+
+// run executes an iterative deepening depth first search.
+func (s *search) run() ([]Solution, error) {
+	h := s.estimate(&s.start)
+	if h == -1 {
+		return nil, ErrNoSolution
+	}
+	for depth := h; depth <= s.maxDepth; depth++ {
+		s.depth = depth
+		s.progress()
+		found := s.dfs(state{robots: s.start}, depth)
+		if s.err != nil {
+			return nil, s.err
+		}
+		if found {
+			return s.solutions, nil
+		}
+	}
+	return nil, ErrNoSolution
+}
+
+func (s *search) dfs(st state, remaining int) bool {
+	r := &st.robots
+	if s.isGoal(r) {
+		s.solutions = append(s.solutions, append(Solution(nil), s.path...))
+		return true
+	}
+	if h := s.estimate(r); h == -1 || h > remaining {
+		return false
+	}
+	key := s.key(&st)
+	if n, ok := s.seen[key]; ok && n >= remaining {
+		return false
+	}
+
+	s.expanded++
+	if s.expanded%ctxCheckInterval == 0 {
+		if s.err = s.ctx.Err(); s.err != nil {
+			return false
+		}
+		s.progress()
+	}
+
+	var buf [board.NumRobot * board.NumDirection]child
+	found := false
+	children := s.children(&st, len(s.path) == 0, buf[:0])
+	if s.observer != nil {
+		s.generated += len(children)
+	}
+	for _, ch := range children {
+		s.path = append(s.path, ch.move)
+		ok := s.dfs(ch.state, remaining-1)
+		s.path = s.path[:len(s.path)-1]
+		if s.err != nil {
+			return false
+		}
+		if ok {
+			if !s.all {
+				return true
+			}
+			found = true
+		}
+	}
+	// the start state is not stored, as the first move constraints only apply there
+	if !found && len(s.path) != 0 {
+		s.seen[key] = remaining
+	}
+	return found
+}
+
+type child struct {
+	state
+	move board.Move
+}
+
+// children appends all successor states of st to buf which satisfy the constraints.
+// Parameter first is true if st is the start state.
+func (s *search) children(st *state, first bool, buf []child) []child {
+	for i, c := range st.robots {
+		if !s.allowed[i] {
+			continue
+		}
+		moved := st.moved
+		if s.maxRobots != 0 {
+			if moved&(1<<i) == 0 && bits.OnesCount8(moved) == s.maxRobots {
+				continue
+			}
+			moved |= 1 << i
+		}
+		for _, d := range board.Directions {
+			m := board.Move{Robot: board.RobotColors[i], Direction: d}
+			if first && s.forbidden[m] {
+				continue
+			}
+			n := s.b.Stop(c, d, &st.robots)
+			if n == c {
+				continue
+			}
+			ch := child{state: state{robots: st.robots, moved: moved}, move: m}
+			ch.robots[i] = n
+			buf = append(buf, ch)
+		}
+	}
+	return buf
+}
+
+// weight of the estimate in the priority of the bounded search
+const boundedWeight = 3
+
+// bounded executes a weighted best first search for a solution with at most n moves.
+// States are expanded in order of moves so far plus the weighted estimate of the
+// moves to the target and only if the moves so far plus the estimate do not exceed n.
+// The search is complete, as states are expanded again if reached with fewer moves.
+// If limit is not 0 the search gives up after expanding limit states.
+func (s *search) bounded(n, weight, limit int) (Solution, bool, error) {
+	h := s.estimate(&s.start)
+	if h == -1 || h > n {
+		return nil, false, nil
+	}
+
+	nodes, best, open := s.nodes[:0], s.best, &s.open
+	clear(best)
+	*open = (*open)[:0]
+	nodes = append(nodes, node{state: state{robots: s.start}, parent: -1})
+	best[s.key(&nodes[0].state)] = 0
+	open.push(item{prio: weight * h})
+	defer func() { s.nodes = nodes[:0] }() // keep memory for reuse
+
+	expanded := 0
+	var buf [board.NumRobot * board.NumDirection]child
+	for len(*open) != 0 {
+		it := open.pop()
+		cur := nodes[it.node]
+		if best[s.key(&cur.state)] < cur.moves { // reached with fewer moves meanwhile
+			continue
+		}
+		if s.isGoal(&cur.robots) {
+			sol := make(Solution, cur.moves)
+			for i := it.node; nodes[i].parent != -1; i = nodes[i].parent {
+				sol[nodes[i].moves-1] = nodes[i].move
+			}
+			return sol, true, nil
+		}
+		if expanded++; expanded == limit {
+			return nil, false, nil
+		}
+		s.expanded++
+		if s.expanded%ctxCheckInterval == 0 {
+			if err := s.ctx.Err(); err != nil {
+				return nil, false, err
+			}
+			s.progress()
+		}
+		children := s.children(&cur.state, it.node == 0, buf[:0])
+		if s.observer != nil {
+			s.depth = max(s.depth, cur.moves)
+			s.generated += len(children)
+		}
+		for _, ch := range children {
+			h := s.estimate(&ch.robots)
+			moves := cur.moves + 1
+			if h == -1 || moves+h > n {
+				continue
+			}
+			key := s.key(&ch.state)
+			if m, ok := best[key]; ok && m <= moves {
+				continue
+			}
+			best[key] = moves
+			nodes = append(nodes, node{state: ch.state, moves: moves, parent: it.node, move: ch.move})
+			open.push(item{node: len(nodes) - 1, prio: moves + weight*h, moves: moves})
+		}
+	}
+	return nil, false, nil
+}
+
+// node is a node of the bounded search.
+type node struct {
+	state
+	moves  int
+	parent int // index of parent node, -1: start node
+	move   board.Move
+}
+
+type item struct {
+	node        int
+	prio, moves int
+}
+
+func (it item) less(o item) bool {
+	return it.prio < o.prio || it.prio == o.prio && it.moves < o.moves
+}
+
+// queue is a binary heap of search nodes ordered by priority and moves.
+type queue []item
+
+func (q *queue) push(it item) {
+	*q = append(*q, it)
+	h := *q
+	for i := len(h) - 1; i > 0; {
+		p := (i - 1) / 2
+		if !h[i].less(h[p]) {
+			break
+		}
+		h[i], h[p] = h[p], h[i]
+		i = p
+	}
+}
+
+func (q *queue) pop() item {
+	h := *q
+	it := h[0]
+	n := len(h) - 1
+	h[0] = h[n]
+	h = h[:n]
+	for i := 0; ; {
+		min, l, r := i, 2*i+1, 2*i+2
+		if l < n && h[l].less(h[min]) {
+			min = l
+		}
+		if r < n && h[r].less(h[min]) {
+			min = r
+		}
+		if min == i {
+			break
+		}
+		h[i], h[min] = h[min], h[i]
+		i = min
+	}
+	*q = h
+	return it
+}
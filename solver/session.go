@@ -0,0 +1,68 @@
+package solver
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-ricrob/game/board"
+)
+
+// Session is a solver session bound to a board, like a game where the board does not
+// change and the robots stay where the previous round left them. Besides the move
+// and min moves tables cached by the board a session keeps the memory of its
+// searches between calls, so solving consecutive rounds needs far less setup.
+// A session is safe for concurrent use.
+type Session struct {
+	b    *board.Board
+	pool sync.Pool // *search
+}
+
+// NewSession returns a new solver session for board b.
+func NewSession(b *board.Board) *Session {
+	return &Session{b: b, pool: sync.Pool{New: func() any { return &search{} }}}
+}
+
+// Board returns the board of the session.
+func (s *Session) Board() *board.Board { return s.b }
+
+func (s *Session) search(ctx context.Context, robots board.Robots, symbol board.Symbol, color board.Color, c *config) (*search, error) {
+	srch := s.pool.Get().(*search)
+	if err := srch.init(ctx, s.b, robots, symbol, color, c); err != nil {
+		s.pool.Put(srch)
+		return nil, err
+	}
+	return srch, nil
+}
+
+// Solve returns an optimal solution of the puzzle given by robot positions and target.
+// If option AllOptimal is set all optimal solutions are returned.
+func (s *Session) Solve(ctx context.Context, robots board.Robots, symbol board.Symbol, color board.Color, opts ...Option) ([]Solution, error) {
+	c := newConfig(opts)
+	srch, err := s.search(ctx, robots, symbol, color, c)
+	if err != nil {
+		return nil, err
+	}
+	defer s.pool.Put(srch)
+	defer srch.done()
+	solutions, err := srch.run()
+	if err != nil {
+		return nil, err
+	}
+	if c.all {
+		solutions = srch.dedupe(solutions)
+		rank(solutions)
+	}
+	return solutions, nil
+}
+
+// Within reports whether the puzzle given by robot positions and target can be solved
+// with at most n moves and returns such a solution (see function Within).
+func (s *Session) Within(ctx context.Context, robots board.Robots, symbol board.Symbol, color board.Color, n int, opts ...Option) (Solution, bool, error) {
+	srch, err := s.search(ctx, robots, symbol, color, newConfig(opts))
+	if err != nil {
+		return nil, false, err
+	}
+	defer s.pool.Put(srch)
+	defer srch.done()
+	return srch.bounded(n, boundedWeight, 0)
+}
@@ -0,0 +1,114 @@
+package solver
+
+import (
+	"sort"
+
+	"github.com/go-ricrob/game/board"
+)
+
+// Solution is a sequence of robot moves solving a puzzle.
+type Solution []board.Move
+
+// String returns the move notation of the solution (see board.FormatMoves).
+func (s Solution) String() string { return board.FormatMoves(s) }
+
+// MarshalText implements the encoding.TextMarshaler interface. The solution is
+// encoded in move notation.
+func (s Solution) MarshalText() ([]byte, error) { return []byte(board.FormatMoves(s)), nil }
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (s *Solution) UnmarshalText(text []byte) error {
+	moves, err := board.ParseMoves(string(text))
+	if err != nil {
+		return err
+	}
+	*s = moves
+	return nil
+}
+
+// NumRobots returns the number of distinct robots moved.
+func (s Solution) NumRobots() int {
+	var moved [board.NumRobot]bool
+	n := 0
+	for _, m := range s {
+		if i := board.RobotIndex(m.Robot); !moved[i] {
+			moved[i] = true
+			n++
+		}
+	}
+	return n
+}
+
+// NumHelperMoves returns the number of moves of robots other than the robot reaching the target.
+func (s Solution) NumHelperMoves() int {
+	if len(s) == 0 {
+		return 0
+	}
+	robot := s[len(s)-1].Robot
+	n := 0
+	for _, m := range s {
+		if m.Robot != robot {
+			n++
+		}
+	}
+	return n
+}
+
+func (s Solution) key() string {
+	b := make([]byte, len(s))
+	for i, m := range s {
+		b[i] = byte(m.Robot)<<4 | byte(m.Direction)
+	}
+	return string(b)
+}
+
+// equivalents returns all solutions which can be derived from solution sol
+// by swapping adjacent moves of different robots leading to the same robot positions.
+func (s *search) equivalents(sol Solution) []Solution {
+	result := []Solution{sol}
+	seen := map[string]bool{sol.key(): true}
+	for i := 0; i < len(result); i++ {
+		cur := result[i]
+		r := s.start
+		for j := 0; j < len(cur)-1; j++ {
+			m1, m2 := cur[j], cur[j+1]
+			if m1.Robot != m2.Robot && s.b.Move(s.b.Move(r, m1), m2) == s.b.Move(s.b.Move(r, m2), m1) {
+				swapped := append(Solution(nil), cur...)
+				swapped[j], swapped[j+1] = m2, m1
+				if k := swapped.key(); !seen[k] {
+					seen[k] = true
+					result = append(result, swapped)
+				}
+			}
+			r = s.b.Move(r, m1)
+		}
+	}
+	return result
+}
+
+// dedupe removes solutions which are equivalent to a preceding solution.
+func (s *search) dedupe(solutions []Solution) []Solution {
+	seen := map[string]bool{}
+	result := []Solution{}
+	for _, sol := range solutions {
+		if seen[sol.key()] {
+			continue
+		}
+		result = append(result, sol)
+		for _, e := range s.equivalents(sol) {
+			seen[e.key()] = true
+		}
+	}
+	return result
+}
+
+// rank sorts solutions by number of distinct robots moved and number of helper moves.
+func rank(solutions []Solution) {
+	sort.SliceStable(solutions, func(i, j int) bool {
+		ri, rj := solutions[i].NumRobots(), solutions[j].NumRobots()
+		if ri != rj {
+			return ri < rj
+		}
+		return solutions[i].NumHelperMoves() < solutions[j].NumHelperMoves()
+	})
+}
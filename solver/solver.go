@@ -0,0 +1,101 @@
+// Package solver provides an optimal solver for robot puzzles.
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-ricrob/game/board"
+)
+
+// ErrNoSolution is returned if a puzzle cannot be solved within the maximal search depth.
+var ErrNoSolution = errors.New("no solution")
+
+const defaultMaxDepth = 30
+
+type config struct {
+	all       bool
+	maxDepth  int
+	allowed   map[board.Color]bool
+	maxRobots int
+	forbidden map[board.Move]bool
+	heuristic Heuristic
+	observer  Observer
+
+	noSymmetry bool // disables the symmetry reduction (benchmarks)
+}
+
+// Option is the type of a solver option.
+type Option func(c *config)
+
+// AllOptimal makes the solver return all optimal solutions instead of a single one.
+// Solutions differing only in the order of independent moves are returned once.
+// The solutions are ranked by the number of distinct robots moved and then by the
+// number of moves of robots other than the target robot.
+func AllOptimal() Option { return func(c *config) { c.all = true } }
+
+// MaxDepth limits the search to solutions of at most n moves (default 30).
+func MaxDepth(n int) Option { return func(c *config) { c.maxDepth = n } }
+
+// AllowedRobots restricts the solver to move the robots of the given colors only.
+// For a cosmic target only the allowed robots are considered to reach the target.
+func AllowedRobots(colors ...board.Color) Option {
+	return func(c *config) {
+		c.allowed = map[board.Color]bool{}
+		for _, color := range colors {
+			c.allowed[color] = true
+		}
+	}
+}
+
+// MaxRobots restricts the solver to move at most n distinct robots (0: no restriction).
+func MaxRobots(n int) Option { return func(c *config) { c.maxRobots = n } }
+
+// ForbiddenFirstMoves excludes the given moves as first move of a solution.
+func ForbiddenFirstMoves(moves ...board.Move) Option {
+	return func(c *config) {
+		c.forbidden = map[board.Move]bool{}
+		for _, m := range moves {
+			c.forbidden[m] = true
+		}
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{maxDepth: defaultMaxDepth}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Solve returns an optimal solution of the puzzle given by board, robot positions and target.
+// If option AllOptimal is set all optimal solutions are returned.
+func Solve(ctx context.Context, b *board.Board, robots board.Robots, symbol board.Symbol, color board.Color, opts ...Option) ([]Solution, error) {
+	return NewSession(b).Solve(ctx, robots, symbol, color, opts...)
+}
+
+// Within reports whether the puzzle given by board, robot positions and target can be
+// solved with at most n moves and returns such a solution. The search stops at the
+// first solution found, which is not necessarily an optimal one.
+func Within(ctx context.Context, b *board.Board, robots board.Robots, symbol board.Symbol, color board.Color, n int, opts ...Option) (Solution, bool, error) {
+	return NewSession(b).Within(ctx, robots, symbol, color, n, opts...)
+}
+
+// targetRobot returns the index of the robot that has to reach the target given by
+// symbol and color, -1 for any robot. An error is returned if board b does not hold
+// the target.
+func targetRobot(b *board.Board, symbol board.Symbol, color board.Color) (int, error) {
+	i := board.RobotIndex(color)
+	switch {
+	case symbol == board.Cosmic && color == 0:
+		i = -1 // any robot
+	case i == -1 || symbol < board.Pyramid || symbol > board.Saturn:
+		return 0, fmt.Errorf("invalid target: symbol %s color %s", symbol, color)
+	}
+	if !b.HasTarget(symbol, color) {
+		return 0, fmt.Errorf("invalid target: no target %s %s on board", symbol, color)
+	}
+	return i, nil
+}
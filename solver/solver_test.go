@@ -0,0 +1,374 @@
+package solver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/go-ricrob/game/board"
+	"github.com/go-ricrob/game/internal/testutil"
+)
+
+// bfs calculates the optimal number of moves by a breadth first search.
+func bfs(b *board.Board, p testutil.Puzzle) int { return constrainedBFS(b, p, nil, 0, nil) }
+
+// constrainedBFS calculates the optimal number of moves by a breadth first search
+// moving the allowed robots only (nil: all), at most maxRobots distinct robots
+// (0: no limit) and excluding the forbidden first moves.
+func constrainedBFS(b *board.Board, p testutil.Puzzle, allowed []board.Color, maxRobots int, forbidden []board.Move) int {
+	type state struct {
+		robots board.Robots
+		moved  [board.NumRobot]bool
+	}
+	isAllowed := func(c board.Color) bool {
+		for _, a := range allowed {
+			if a == c {
+				return true
+			}
+		}
+		return allowed == nil
+	}
+	isForbidden := func(m board.Move) bool {
+		for _, f := range forbidden {
+			if f == m {
+				return true
+			}
+		}
+		return false
+	}
+	target := b.TargetCoord(p.Symbol, p.Color)
+	robot := board.RobotIndex(p.Color)
+	isGoal := func(r *board.Robots) bool {
+		if robot == -1 {
+			for i, c := range r {
+				if c == target && isAllowed(board.RobotColors[i]) {
+					return true
+				}
+			}
+			return false
+		}
+		return r[robot] == target
+	}
+	start := state{robots: p.Robots}
+	seen := map[state]bool{start: true}
+	level := []state{start}
+	for depth := 0; len(level) != 0; depth++ {
+		var next []state
+		for _, st := range level {
+			if isGoal(&st.robots) {
+				return depth
+			}
+			numMoved := 0
+			for _, moved := range st.moved {
+				if moved {
+					numMoved++
+				}
+			}
+			for i, c := range board.RobotColors {
+				if !isAllowed(c) || maxRobots != 0 && !st.moved[i] && numMoved == maxRobots {
+					continue
+				}
+				for _, d := range board.Directions {
+					m := board.Move{Robot: c, Direction: d}
+					if depth == 0 && isForbidden(m) {
+						continue
+					}
+					n := state{robots: b.Move(st.robots, m), moved: st.moved}
+					n.moved[i] = true
+					if n.robots != st.robots && !seen[n] {
+						seen[n] = true
+						next = append(next, n)
+					}
+				}
+			}
+		}
+		level = next
+	}
+	return -1
+}
+
+func TestSolve(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	n := 20
+	if testing.Short() {
+		n = 5
+	}
+	for _, p := range testutil.RandomPuzzles(b, n) {
+		solutions, err := Solve(context.Background(), b, p.Robots, p.Symbol, p.Color)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(solutions) != 1 {
+			t.Fatalf("%v: invalid number of solutions %d - expected 1", p, len(solutions))
+		}
+		testutil.CheckSolution(t, b, p, solutions[0])
+		opt := bfs(b, p)
+		if len(solutions[0]) != opt {
+			t.Fatalf("%v: solution %s with %d moves - expected %d moves", p, solutions[0], len(solutions[0]), opt)
+		}
+		sol, err := SolveBidirectional(context.Background(), b, p.Robots, p.Symbol, p.Color)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testutil.CheckSolution(t, b, p, sol)
+		if len(sol) != opt {
+			t.Fatalf("%v: bidirectional solution %s with %d moves - expected %d moves", p, sol, len(sol), opt)
+		}
+	}
+}
+
+func TestSolveBidirectionalOptions(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	p := testutil.RandomPuzzles(b, 1)[0]
+	for _, opt := range []Option{AllOptimal(), MaxRobots(1), ForbiddenFirstMoves(board.Move{Robot: board.Red, Direction: board.North})} {
+		if _, err := SolveBidirectional(context.Background(), b, p.Robots, p.Symbol, p.Color, opt); !errors.Is(err, ErrUnsupportedOption) {
+			t.Fatalf("%v: error %v - expected %v", p, err, ErrUnsupportedOption)
+		}
+	}
+	// disabled options are fine
+	if _, err := SolveBidirectional(context.Background(), b, p.Robots, p.Symbol, p.Color, MaxRobots(0), ForbiddenFirstMoves()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSolveInvalidTarget(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	p := testutil.RandomPuzzles(b, 1)[0]
+	c := b.TargetCoord(board.Moon, board.Green)
+	if err := b.SetTarget(int(c>>4), int(c&0x0f), board.NoSymbol, 0); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, target := range []testutil.Puzzle{
+		{Symbol: board.Cosmic, Color: board.Red}, // cosmic target with color
+		{Symbol: board.Moon, Color: board.Green}, // target not on board
+	} {
+		if _, err := Solve(ctx, b, p.Robots, target.Symbol, target.Color); err == nil {
+			t.Fatalf("solve %s %s: error expected", target.Symbol, target.Color)
+		}
+		if _, _, err := Within(ctx, b, p.Robots, target.Symbol, target.Color, 5); err == nil {
+			t.Fatalf("within %s %s: error expected", target.Symbol, target.Color)
+		}
+		if err := Anytime(ctx, b, p.Robots, target.Symbol, target.Color, func(Result) {}); err == nil {
+			t.Fatalf("anytime %s %s: error expected", target.Symbol, target.Color)
+		}
+		if _, err := SolveBidirectional(ctx, b, p.Robots, target.Symbol, target.Color); err == nil {
+			t.Fatalf("bidirectional %s %s: error expected", target.Symbol, target.Color)
+		}
+	}
+}
+
+func TestSolveAllOptimal(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	for _, p := range testutil.RandomPuzzles(b, 20) {
+		solutions, err := Solve(context.Background(), b, p.Robots, p.Symbol, p.Color)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all, err := Solve(context.Background(), b, p.Robots, p.Symbol, p.Color, AllOptimal())
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := &search{b: b, start: p.Robots}
+		seen := map[string]bool{}
+		for i, sol := range all {
+			testutil.CheckSolution(t, b, p, sol)
+			if len(sol) != len(solutions[0]) {
+				t.Fatalf("%v: solution %s is not optimal", p, sol)
+			}
+			if i > 0 {
+				prev := all[i-1]
+				if prev.NumRobots() > sol.NumRobots() || prev.NumRobots() == sol.NumRobots() && prev.NumHelperMoves() > sol.NumHelperMoves() {
+					t.Fatalf("%v: invalid ranking of solutions %s and %s", p, prev, sol)
+				}
+			}
+			for _, e := range s.equivalents(sol) {
+				if seen[e.key()] {
+					t.Fatalf("%v: duplicate solution %s", p, sol)
+				}
+				seen[e.key()] = true
+			}
+		}
+	}
+}
+
+func TestSolutionText(t *testing.T) {
+	sol := Solution{{Robot: board.Red, Direction: board.North}, {Robot: board.Blue, Direction: board.West}}
+	data, err := json.Marshal(sol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"R↑ B←"` {
+		t.Fatalf("got %s - expected \"R↑ B←\"", data)
+	}
+	var got Solution
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != sol.String() {
+		t.Fatalf("got %s - expected %s", got, sol)
+	}
+	if err := json.Unmarshal([]byte(`"R↑ X←"`), &got); err == nil {
+		t.Fatal("invalid notation: error expected")
+	}
+}
+
+func TestWithin(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	for _, p := range testutil.RandomPuzzles(b, 20) {
+		solutions, err := Solve(context.Background(), b, p.Robots, p.Symbol, p.Color)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opt := len(solutions[0])
+		for n := opt - 1; n <= opt+5; n++ {
+			sol, ok, err := Within(context.Background(), b, p.Robots, p.Symbol, p.Color, n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != (n >= opt) {
+				t.Fatalf("%v: within %d moves %t - expected %t", p, n, ok, n >= opt)
+			}
+			if ok {
+				testutil.CheckSolution(t, b, p, sol)
+				if len(sol) > n {
+					t.Fatalf("%v: solution %s exceeds %d moves", p, sol, n)
+				}
+			}
+		}
+	}
+}
+
+func TestSolveConstraints(t *testing.T) {
+	b := board.New(testutil.DefaultTiles)
+	for i, p := range testutil.RandomPuzzles(b, 10) {
+		allowed := []board.Color{board.RobotColors[i%board.NumRobot], board.RobotColors[(i+1)%board.NumRobot]}
+		if p.Color != 0 && i%2 == 0 {
+			allowed[0] = p.Color
+		}
+		forbidden := []board.Move{{Robot: allowed[0], Direction: board.North}, {Robot: allowed[0], Direction: board.West}}
+
+		for _, test := range []struct {
+			opts      []Option
+			allowed   []board.Color
+			maxRobots int
+			forbidden []board.Move
+		}{
+			{opts: []Option{AllowedRobots(allowed...)}, allowed: allowed},
+			{opts: []Option{MaxRobots(1)}, maxRobots: 1},
+			{opts: []Option{MaxRobots(2)}, maxRobots: 2},
+			{opts: []Option{ForbiddenFirstMoves(forbidden...)}, forbidden: forbidden},
+			{opts: []Option{AllowedRobots(allowed...), ForbiddenFirstMoves(forbidden...), AllOptimal()}, allowed: allowed, forbidden: forbidden},
+		} {
+			opt := constrainedBFS(b, p, test.allowed, test.maxRobots, test.forbidden)
+			solutions, err := Solve(context.Background(), b, p.Robots, p.Symbol, p.Color, append(test.opts, MaxDepth(12))...)
+			if opt == -1 || opt > 12 {
+				if err != ErrNoSolution {
+					t.Fatalf("%v: error %v - expected %v", p, err, ErrNoSolution)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, sol := range solutions {
+				testutil.CheckSolution(t, b, p, sol)
+				if len(sol) != opt {
+					t.Fatalf("%v: solution %s with %d moves - expected %d moves", p, sol, len(sol), opt)
+				}
+				if test.maxRobots != 0 && sol.NumRobots() > test.maxRobots {
+					t.Fatalf("%v: solution %s moves more than %d robots", p, sol, test.maxRobots)
+				}
+				for _, m := range sol {
+					if test.allowed != nil && m.Robot != test.allowed[0] && m.Robot != test.allowed[1] {
+						t.Fatalf("%v: solution %s moves robot %s", p, sol, m.Robot)
+					}
+				}
+				for _, m := range test.forbidden {
+					if len(sol) != 0 && sol[0] == m {
+						t.Fatalf("%v: solution %s starts with forbidden move", p, sol)
+					}
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkSolve(b *testing.B) {
+	brd := board.New(testutil.DefaultTiles)
+	puzzles := testutil.RandomPuzzles(brd, 20)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, p := range puzzles {
+			if _, err := Solve(context.Background(), brd, p.Robots, p.Symbol, p.Color); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkWithin(b *testing.B) {
+	brd := board.New(testutil.DefaultTiles)
+	puzzles := testutil.RandomPuzzles(brd, 20)
+	bounds := make([]int, len(puzzles))
+	for i, p := range puzzles {
+		solutions, err := Solve(context.Background(), brd, p.Robots, p.Symbol, p.Color)
+		if err != nil {
+			b.Fatal(err)
+		}
+		bounds[i] = len(solutions[0]) + 5
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j, p := range puzzles {
+			if _, ok, err := Within(context.Background(), brd, p.Robots, p.Symbol, p.Color, bounds[j]); !ok || err != nil {
+				b.Fatal(ok, err)
+			}
+		}
+	}
+}
+
+// BenchmarkGame solves the rounds of a game for all targets, each round starting
+// with the robot positions the previous round left.
+func BenchmarkGame(b *testing.B) {
+	brd := board.New(testutil.DefaultTiles)
+	start := testutil.RandomPuzzles(brd, 1)[0].Robots
+	type target struct {
+		symbol board.Symbol
+		color  board.Color
+	}
+	targets := []target{{symbol: board.Cosmic}}
+	for _, symbol := range board.Symbols[:4] {
+		for _, color := range board.RobotColors {
+			targets = append(targets, target{symbol, color})
+		}
+	}
+
+	play := func(b *testing.B, solve func(robots board.Robots, t target) ([]Solution, error)) {
+		for i := 0; i < b.N; i++ {
+			robots := start
+			for _, t := range targets {
+				solutions, err := solve(robots, t)
+				if err != nil {
+					b.Fatal(err)
+				}
+				for _, m := range solutions[0] {
+					robots = brd.Move(robots, m)
+				}
+			}
+		}
+	}
+
+	b.Run("Solve", func(b *testing.B) {
+		play(b, func(robots board.Robots, t target) ([]Solution, error) {
+			return Solve(context.Background(), brd, robots, t.symbol, t.color)
+		})
+	})
+	b.Run("Session", func(b *testing.B) {
+		session := NewSession(brd)
+		play(b, func(robots board.Robots, t target) ([]Solution, error) {
+			return session.Solve(context.Background(), robots, t.symbol, t.color)
+		})
+	})
+}